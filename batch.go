@@ -0,0 +1,630 @@
+package oc
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchConcurrency = 4
+	defaultBatchRetries     = 3
+	defaultBatchRetryDelay  = time.Second
+
+	pictureMetadataMimetype = "application/vnd.iptc.g2.newsitem+xml.picture"
+	newsItemMimetype        = "application/vnd.iptc.g2.newsitem+xml"
+)
+
+// BatchOptions controls the behaviour of a BatchUploader.
+type BatchOptions struct {
+	// Concurrency is the number of uploads that run in parallel.
+	// Defaults to 4.
+	Concurrency int
+	// MaxRetries is the number of times a transient upload failure
+	// is retried. Defaults to 3, a negative value disables retries.
+	// Uploads without a UUID or UUIDKey create a new object on every
+	// attempt, so they're only retried when OC rate limits them.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, it's doubled
+	// for every following retry. Defaults to one second.
+	RetryDelay time.Duration
+	// Source is used for uploads that don't specify a source.
+	Source string
+	// Unit is used for uploads that don't specify a unit.
+	Unit string
+	// MetadataMimetype is the mimetype used for sidecar metadata
+	// files. Defaults to the NewsML picture mimetype for images and
+	// the plain NewsML mimetype for anything else.
+	MetadataMimetype string
+	// OnResult is called with the result of every item as it
+	// completes. Calls are serialised.
+	OnResult func(BatchResult)
+}
+
+// BatchItem is a single upload in a batch.
+type BatchItem struct {
+	// Path identifies the item in the batch report.
+	Path    string
+	Request UploadRequest
+}
+
+// BatchResult is the outcome of a single batch item.
+type BatchResult struct {
+	Path     string `json:"path"`
+	UUID     string `json:"uuid,omitempty"`
+	Version  int64  `json:"version,omitempty"`
+	ETag     string `json:"etag,omitempty"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+
+	// Err is the error that caused the item to fail.
+	Err error `json:"-"`
+}
+
+// BatchReport lists the results of a batch upload in input order.
+type BatchReport struct {
+	Results []BatchResult `json:"results"`
+}
+
+// Failed returns the results of the items that failed.
+func (r *BatchReport) Failed() []BatchResult {
+	var failed []BatchResult
+
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+// WriteJSON writes the report as JSON.
+func (r *BatchReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes the report as CSV with a header row.
+func (r *BatchReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write([]string{"path", "uuid", "version", "etag", "attempts", "error"})
+
+	for _, res := range r.Results {
+		version := ""
+		if res.Version != 0 {
+			version = strconv.FormatInt(res.Version, 10)
+		}
+
+		_ = cw.Write([]string{
+			res.Path, res.UUID, version, res.ETag,
+			strconv.Itoa(res.Attempts), res.Error,
+		})
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// BatchUploader uploads objects with bounded concurrency. All uploads
+// are made as batch uploads.
+type BatchUploader struct {
+	client *Client
+	opts   BatchOptions
+}
+
+// NewBatchUploader creates a batch uploader for the client.
+func (c *Client) NewBatchUploader(opts *BatchOptions) *BatchUploader {
+	var options BatchOptions
+
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultBatchConcurrency
+	}
+
+	if options.MaxRetries == 0 {
+		options.MaxRetries = defaultBatchRetries
+	}
+
+	if options.RetryDelay == 0 {
+		options.RetryDelay = defaultBatchRetryDelay
+	}
+
+	return &BatchUploader{
+		client: c,
+		opts:   options,
+	}
+}
+
+// UploadBatch uploads the files in a directory tree, see
+// BatchUploader.UploadDir.
+func (c *Client) UploadBatch(ctx context.Context, dir string, opts *BatchOptions) (*BatchReport, error) {
+	return c.NewBatchUploader(opts).UploadDir(ctx, dir)
+}
+
+// batchTask is a unit of work for the upload workers. open is called
+// before every attempt and returns the request to upload together
+// with a function that releases its resources.
+type batchTask struct {
+	index int
+	path  string
+	open  func(attempt int) (UploadRequest, func(), error)
+}
+
+// Upload uploads the items received on the channel until it's closed.
+// Items whose readers implement io.Seeker are rewound and retried on
+// transient failures, other items are only attempted once. The
+// returned error is only set if the context was cancelled, in which
+// case the report covers the items that were processed.
+func (b *BatchUploader) Upload(ctx context.Context, items <-chan BatchItem) (*BatchReport, error) {
+	tasks := make(chan batchTask)
+
+	go func() {
+		defer close(tasks)
+
+		var index int
+
+		for {
+			var (
+				item BatchItem
+				ok   bool
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok = <-items:
+			}
+
+			if !ok {
+				return
+			}
+
+			task := batchTask{
+				index: index,
+				path:  item.Path,
+				open:  itemOpener(item),
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case tasks <- task:
+			}
+
+			index++
+		}
+	}()
+
+	return b.run(ctx, tasks)
+}
+
+func itemOpener(item BatchItem) func(attempt int) (UploadRequest, func(), error) {
+	return func(attempt int) (UploadRequest, func(), error) {
+		if attempt == 0 {
+			return item.Request, func() {}, nil
+		}
+
+		for field, f := range item.Request.Files {
+			s, ok := f.Reader.(io.Seeker)
+			if !ok {
+				return UploadRequest{}, nil, fmt.Errorf(
+					"can't retry, the reader for %q isn't seekable", field)
+			}
+
+			if _, err := s.Seek(0, io.SeekStart); err != nil {
+				return UploadRequest{}, nil, fmt.Errorf(
+					"failed to rewind %q: %w", field, err)
+			}
+		}
+
+		return item.Request, func() {}, nil
+	}
+}
+
+// UploadDir walks a directory tree and uploads every file as the
+// primary file of an object. Hidden files and directories are
+// skipped. A metadata file next to the primary file named
+// "<name>.xml", "<stem>.xml" or "<stem>.metadata.xml" is uploaded as
+// its sidecar metadata, so "photo.jpg" is paired with "photo.xml".
+// Mimetypes are detected from the file extension or, failing that,
+// the file contents.
+func (b *BatchUploader) UploadDir(ctx context.Context, dir string) (*BatchReport, error) {
+	pairs, err := collectBatchFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(chan batchTask)
+
+	go func() {
+		defer close(tasks)
+
+		for i, p := range pairs {
+			task := batchTask{
+				index: i,
+				path:  p.primary,
+				open:  b.fileOpener(p),
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case tasks <- task:
+			}
+		}
+	}()
+
+	return b.run(ctx, tasks)
+}
+
+type batchFilePair struct {
+	primary  string
+	metadata string
+}
+
+func collectBatchFiles(dir string) ([]batchFilePair, error) {
+	var paths []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %q: %w", dir, err)
+	}
+
+	exists := make(map[string]bool, len(paths))
+
+	for _, p := range paths {
+		exists[p] = true
+	}
+
+	sidecars := make(map[string]string)
+	used := make(map[string]bool)
+
+	for _, p := range paths {
+		if isXMLFile(p) {
+			continue
+		}
+
+		stem := strings.TrimSuffix(p, filepath.Ext(p))
+
+		for _, candidate := range []string{p + ".xml", stem + ".xml", stem + ".metadata.xml"} {
+			if exists[candidate] && !used[candidate] {
+				sidecars[p] = candidate
+				used[candidate] = true
+
+				break
+			}
+		}
+	}
+
+	var pairs []batchFilePair
+
+	for _, p := range paths {
+		if used[p] {
+			continue
+		}
+
+		pairs = append(pairs, batchFilePair{
+			primary:  p,
+			metadata: sidecars[p],
+		})
+	}
+
+	return pairs, nil
+}
+
+func isXMLFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".xml")
+}
+
+func (b *BatchUploader) fileOpener(p batchFilePair) func(attempt int) (UploadRequest, func(), error) {
+	return func(_ int) (UploadRequest, func(), error) {
+		var opened []*os.File
+
+		release := func() {
+			for _, f := range opened {
+				safeClose(b.client.logger, "batch file", f)
+			}
+		}
+
+		open := func(path string) (File, error) {
+			mimetype, err := detectMimetype(path)
+			if err != nil {
+				return File{}, err
+			}
+
+			f, err := os.Open(path) //nolint:gosec
+			if err != nil {
+				return File{}, fmt.Errorf("failed to open file: %w", err)
+			}
+
+			opened = append(opened, f)
+
+			return File{
+				Name:     filepath.Base(path),
+				Reader:   f,
+				Mimetype: mimetype,
+			}, nil
+		}
+
+		primary, err := open(p.primary)
+		if err != nil {
+			release()
+
+			return UploadRequest{}, nil, err
+		}
+
+		req := UploadRequest{
+			Files: FileSet{"file": primary},
+		}
+
+		if p.metadata != "" {
+			metadata, err := open(p.metadata)
+			if err != nil {
+				release()
+
+				return UploadRequest{}, nil, err
+			}
+
+			metadata.Mimetype = b.metadataMimetype(primary.Mimetype)
+
+			req.Files["metadata"] = metadata
+		}
+
+		return req, release, nil
+	}
+}
+
+func (b *BatchUploader) metadataMimetype(primary string) string {
+	if b.opts.MetadataMimetype != "" {
+		return b.opts.MetadataMimetype
+	}
+
+	if strings.HasPrefix(primary, "image/") {
+		return pictureMetadataMimetype
+	}
+
+	return newsItemMimetype
+}
+
+// detectMimetype guesses the mimetype of a file from its extension,
+// falling back on sniffing the contents.
+func detectMimetype(path string) (string, error) {
+	if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+		mediaType, _, err := mime.ParseMediaType(byExt)
+		if err == nil {
+			return mediaType, nil
+		}
+	}
+
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+
+	defer f.Close() //nolint:errcheck
+
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", fmt.Errorf("failed to detect mimetype: %w", err)
+	}
+
+	return mediaType, nil
+}
+
+func (b *BatchUploader) run(ctx context.Context, tasks <-chan batchTask) (*BatchReport, error) {
+	var (
+		wg      sync.WaitGroup
+		m       sync.Mutex
+		results []indexedResult
+	)
+
+	for i := 0; i < b.opts.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for task := range tasks {
+				res := b.uploadWithRetries(ctx, task)
+
+				m.Lock()
+
+				results = append(results, indexedResult{task.index, res})
+
+				if b.opts.OnResult != nil {
+					b.opts.OnResult(res)
+				}
+
+				m.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].index < results[j].index
+	})
+
+	report := BatchReport{
+		Results: make([]BatchResult, len(results)),
+	}
+
+	for i := range results {
+		report.Results[i] = results[i].result
+	}
+
+	if err := ctx.Err(); err != nil {
+		return &report, fmt.Errorf("batch upload was interrupted: %w", err)
+	}
+
+	return &report, nil
+}
+
+type indexedResult struct {
+	index  int
+	result BatchResult
+}
+
+func (b *BatchUploader) uploadWithRetries(ctx context.Context, task batchTask) BatchResult {
+	result := BatchResult{Path: task.path}
+	delay := b.opts.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		res, idempotent, err := b.upload(ctx, task, attempt)
+
+		result.Attempts = attempt + 1
+
+		if err == nil {
+			result.UUID = res.UUID
+			result.Version = res.Version
+			result.ETag = res.ETag
+
+			return result
+		}
+
+		if attempt >= b.opts.MaxRetries || !isTransient(ctx, err, idempotent) {
+			result.Err = err
+			result.Error = err.Error()
+
+			return result
+		}
+
+		b.client.logger.Logf("retrying upload of %q: %v", task.path, err)
+
+		select {
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			result.Error = result.Err.Error()
+
+			return result
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// errNotRetryable marks errors that happened before the upload was
+// attempted.
+type errNotRetryable struct {
+	err error
+}
+
+func (e errNotRetryable) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotRetryable) Unwrap() error {
+	return e.err
+}
+
+// upload makes an upload attempt and reports whether the upload can
+// be repeated without creating another object.
+func (b *BatchUploader) upload(
+	ctx context.Context, task batchTask, attempt int,
+) (*UploadResponse, bool, error) {
+	req, release, err := task.open(attempt)
+	if err != nil {
+		return nil, false, errNotRetryable{err}
+	}
+
+	defer release()
+
+	req.Batch = true
+
+	if req.Source == "" {
+		req.Source = b.opts.Source
+	}
+
+	if req.Unit == "" {
+		req.Unit = b.opts.Unit
+	}
+
+	idempotent := req.objectUUID() != ""
+
+	res, err := b.client.Upload(ctx, req)
+
+	return res, idempotent, err
+}
+
+// isTransient reports whether a failed request is worth retrying:
+// rate limiting, and network and server errors for idempotent
+// requests. A non-idempotent request could have succeeded even if the
+// response was lost, so it's only retried when OC rate limited it.
+func isTransient(ctx context.Context, err error, idempotent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var nr errNotRetryable
+
+	if errors.As(err, &nr) {
+		return false
+	}
+
+	var re *ResponseError
+
+	if errors.As(err, &re) {
+		code := re.Response.StatusCode
+
+		return code == http.StatusTooManyRequests || (idempotent && code >= 500)
+	}
+
+	return idempotent
+}
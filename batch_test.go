@@ -0,0 +1,241 @@
+package oc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestClient_UploadBatch(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	dir := writeTestFiles(t, map[string]string{
+		"a/photo.jpg":         "jpeg data",
+		"a/photo.xml":         "<newsItem>photo</newsItem>",
+		"a/logo.png":          "png data",
+		"a/logo.metadata.xml": "<newsItem>logo</newsItem>",
+		"b/article.xml":       "<newsItem>article</newsItem>",
+		"b/.hidden":           "ignored",
+		".git/config":         "ignored",
+	})
+
+	// The uploads don't have UUIDs, so only rate limiting is retried.
+	fake.UploadFailures = 2
+	fake.UploadFailureStatus = http.StatusTooManyRequests
+
+	var seen int
+
+	report, err := client.UploadBatch(context.Background(), dir, &oc.BatchOptions{
+		Concurrency: 2,
+		RetryDelay:  time.Millisecond,
+		Source:      "batch-test",
+		OnResult: func(_ oc.BatchResult) {
+			seen++
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to upload batch: %v", err)
+	}
+
+	if len(report.Results) != 3 || seen != 3 {
+		t.Fatalf("expected 3 results, got %d (%d callbacks)", len(report.Results), seen)
+	}
+
+	if failed := report.Failed(); len(failed) != 0 {
+		t.Fatalf("expected no failures, got %v", failed[0].Err)
+	}
+
+	wantPaths := []string{"a/logo.png", "a/photo.jpg", "b/article.xml"}
+
+	var attempts int
+
+	for i, res := range report.Results {
+		if res.Path != filepath.Join(dir, filepath.FromSlash(wantPaths[i])) {
+			t.Errorf("expected result %d to be %q, got %q", i, wantPaths[i], res.Path)
+		}
+
+		attempts += res.Attempts
+
+		v, _ := fake.Version(res.UUID, res.Version)
+		if v == nil {
+			t.Errorf("%q wasn't stored", res.Path)
+
+			continue
+		}
+
+		if !v.Batch || v.Source != "batch-test" {
+			t.Errorf("expected a batch upload from batch-test, got %v from %q", v.Batch, v.Source)
+		}
+	}
+
+	if attempts != 5 {
+		t.Errorf("expected two retries in total, got %d attempts", attempts)
+	}
+
+	photo, _ := fake.Version(report.Results[1].UUID, 0)
+
+	if len(photo.Metadata) != 1 || photo.Metadata[0] != "photo.xml" {
+		t.Fatalf("expected photo.xml as sidecar metadata, got %v", photo.Metadata)
+	}
+
+	if mt := photo.Files["photo.xml"].Mimetype; mt != "application/vnd.iptc.g2.newsitem+xml.picture" {
+		t.Errorf("unexpected sidecar mimetype %q", mt)
+	}
+
+	if mt := photo.Files["photo.jpg"].Mimetype; mt != "image/jpeg" {
+		t.Errorf("unexpected primary mimetype %q", mt)
+	}
+
+	var buf bytes.Buffer
+
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+
+	if len(rows) != 4 || rows[0][0] != "path" || rows[2][1] != report.Results[1].UUID {
+		t.Errorf("unexpected CSV report: %v", rows)
+	}
+}
+
+func TestBatchUploader_Upload(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	items := make(chan oc.BatchItem)
+
+	go func() {
+		defer close(items)
+
+		items <- oc.BatchItem{
+			Path: "first",
+			Request: oc.UploadRequest{
+				UUID: testImageUUID,
+				Files: oc.FileSet{"file": {
+					Name:     "first.jpeg",
+					Reader:   bytes.NewReader([]byte("first")),
+					Mimetype: "image/jpeg",
+				}},
+			},
+		}
+
+		// Missing primary file, OC will reject it.
+		items <- oc.BatchItem{
+			Path: "broken",
+			Request: oc.UploadRequest{
+				Files: oc.FileSet{"metadata": {
+					Name:     "broken.xml",
+					Reader:   bytes.NewReader([]byte("<newsItem/>")),
+					Mimetype: "application/vnd.iptc.g2.newsitem+xml",
+				}},
+			},
+		}
+	}()
+
+	uploader := client.NewBatchUploader(&oc.BatchOptions{
+		RetryDelay: time.Millisecond,
+	})
+
+	report, err := uploader.Upload(context.Background(), items)
+	if err != nil {
+		t.Fatalf("failed to upload batch: %v", err)
+	}
+
+	if len(report.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(report.Results))
+	}
+
+	first, broken := report.Results[0], report.Results[1]
+
+	if first.Err != nil || first.UUID != testImageUUID {
+		t.Errorf("unexpected result for the first item: %+v", first)
+	}
+
+	if broken.Err == nil || broken.Attempts != 1 {
+		t.Errorf("expected the broken item to fail without retries, got %+v", broken)
+	}
+
+	if fake.Uploads != 1 {
+		t.Errorf("expected one stored upload, got %d", fake.Uploads)
+	}
+}
+
+func TestBatchUploader_NonIdempotentRetries(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	upload := func(uuid string, maxRetries int) oc.BatchResult {
+		t.Helper()
+
+		items := make(chan oc.BatchItem, 1)
+
+		items <- oc.BatchItem{
+			Path: "image",
+			Request: oc.UploadRequest{
+				UUID: uuid,
+				Files: oc.FileSet{"file": {
+					Name:     "image.jpeg",
+					Reader:   bytes.NewReader([]byte("image")),
+					Mimetype: "image/jpeg",
+				}},
+			},
+		}
+
+		close(items)
+
+		fake.UploadFailures = 1
+
+		report, err := client.NewBatchUploader(&oc.BatchOptions{
+			MaxRetries: maxRetries,
+			RetryDelay: time.Millisecond,
+		}).Upload(context.Background(), items)
+		if err != nil {
+			t.Fatalf("failed to upload batch: %v", err)
+		}
+
+		return report.Results[0]
+	}
+
+	// The upload could have created an object even if it failed, a
+	// retry without a UUID would create another one.
+	if res := upload("", 0); res.Err == nil || res.Attempts != 1 {
+		t.Errorf("expected an upload without UUID to fail without retries, got %+v", res)
+	}
+
+	if res := upload(testImageUUID, 0); res.Err != nil || res.Attempts != 2 {
+		t.Errorf("expected an upload with UUID to be retried, got %+v", res)
+	}
+
+	if res := upload(testImageUUID, -1); res.Err == nil || res.Attempts != 1 {
+		t.Errorf("expected no retries with negative MaxRetries, got %+v", res)
+	}
+}
@@ -0,0 +1,401 @@
+package oc
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+// BundleManifestName is the name of the manifest entry in an object
+// bundle.
+const BundleManifestName = "manifest.json"
+
+const bundleFileDir = "files/"
+
+// BundleManifest describes an object version in a bundle.
+type BundleManifest struct {
+	UUID        string       `json:"uuid"`
+	Version     int64        `json:"version"`
+	ETag        string       `json:"etag"`
+	ContentType string       `json:"contentType"`
+	Created     *time.Time   `json:"created,omitempty"`
+	Updated     *time.Time   `json:"updated,omitempty"`
+	Properties  []Property   `json:"properties"`
+	Files       []BundleFile `json:"files"`
+}
+
+// BundleFile describes a file in a bundle. Field is the upload form
+// field that the file was, or will be, uploaded as.
+type BundleFile struct {
+	Field    string `json:"field"`
+	Name     string `json:"name"`
+	Mimetype string `json:"mimetype"`
+}
+
+// ExportObject writes all files of an object version, together with a
+// JSON manifest, as a tar stream to w. The manifest is always the
+// first entry of the stream, followed by the files under "files/".
+// If version is 0 the current version is exported.
+func (c *Client) ExportObject(ctx context.Context, uuid string, version int64, w io.Writer) (*BundleManifest, error) {
+	manifest, err := c.bundleManifest(ctx, uuid, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tw := tar.NewWriter(w)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    BundleManifestName,
+		Mode:    0o644,
+		Size:    int64(len(manifestData)),
+		ModTime: bundleModTime(manifest),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write manifest header: %w", err)
+	}
+
+	if _, err := tw.Write(manifestData); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	for _, f := range manifest.Files {
+		err := c.exportFile(ctx, tw, manifest, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func bundleModTime(m *BundleManifest) time.Time {
+	if m.Updated != nil {
+		return *m.Updated
+	}
+
+	return time.Now()
+}
+
+func (c *Client) bundleManifest(ctx context.Context, uuid string, version int64) (*BundleManifest, error) {
+	etag, version, err := c.headObject(ctx, uuid, version)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.ListFiles(ctx, uuid, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	props, err := c.PropertiesVersion(ctx, uuid, version, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get properties: %w", err)
+	}
+
+	manifest := BundleManifest{
+		UUID:        uuid,
+		Version:     version,
+		ETag:        etag,
+		ContentType: props.ContentType,
+		Created:     list.Created,
+		Updated:     list.Updated,
+		Properties:  props.Properties,
+	}
+
+	add := func(field string, f ObjectFile) {
+		if f.Name == "" {
+			return
+		}
+
+		for _, existing := range manifest.Files {
+			if existing.Name == f.Name {
+				return
+			}
+		}
+
+		manifest.Files = append(manifest.Files, BundleFile{
+			Field:    field,
+			Name:     f.Name,
+			Mimetype: f.Mimetype,
+		})
+	}
+
+	if list.Primary.Name == "" {
+		return nil, fmt.Errorf("version %d has no primary file", version)
+	}
+
+	add("file", list.Primary)
+
+	for i, f := range list.uploadMetadata() {
+		add(metadataField(i), f)
+	}
+
+	add("preview", list.Preview)
+	add("thumb", list.Thumb)
+
+	return &manifest, nil
+}
+
+// headObject returns the ETag and version of an object version.
+func (c *Client) headObject(ctx context.Context, uuid string, version int64) (string, int64, error) {
+	q := url.Values{}
+
+	if version != 0 {
+		q.Set("version", strconv.FormatInt(version, 10))
+	}
+
+	res, err := c.fetch(ctx, joinPath("objects", uuid), q,
+		fetchWithMethod(http.MethodHead))
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer safeClose(c.logger, "object head body", res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return "", 0, newResponseError(res)
+	}
+
+	v, err := objectVersionFromHeader(res.Header, versionRequired)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return res.Header.Get("ETag"), v, nil
+}
+
+func (c *Client) exportFile(ctx context.Context, tw *tar.Writer, m *BundleManifest, f BundleFile) error {
+	res, err := c.fetchFile(ctx, m.UUID, f.Name, m.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get file %q: %w", f.Name, err)
+	}
+
+	defer safeClose(c.logger, "export file body", res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get file %q: %w", f.Name, newResponseError(res))
+	}
+
+	var body io.Reader = res.Body
+
+	size := res.ContentLength
+
+	// The tar header needs the size up front, so spool the file to
+	// disk if OC doesn't tell us how large it is.
+	if size < 0 {
+		spool, err := spoolToTemp(res.Body)
+		if err != nil {
+			return fmt.Errorf("failed to buffer file %q: %w", f.Name, err)
+		}
+
+		defer removeTemp(c, spool)
+
+		info, err := spool.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat buffered file %q: %w", f.Name, err)
+		}
+
+		body = spool
+		size = info.Size()
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    bundleFileDir + f.Name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: bundleModTime(m),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write header for file %q: %w", f.Name, err)
+	}
+
+	if _, err := io.Copy(tw, body); err != nil {
+		return fmt.Errorf("failed to write file %q: %w", f.Name, err)
+	}
+
+	return nil
+}
+
+func spoolToTemp(r io.Reader) (*os.File, error) {
+	tmp, err := os.CreateTemp("", "oc-bundle-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	return tmp, nil
+}
+
+func removeTemp(c *Client, f *os.File) {
+	safeClose(c.logger, "temporary file", f)
+
+	if err := os.Remove(f.Name()); err != nil {
+		c.logger.Logf("failed to remove temporary file: %v", err)
+	}
+}
+
+// ImportOptions controls how a bundle is imported.
+type ImportOptions struct {
+	// UUID overrides the UUID from the bundle manifest.
+	UUID string
+	// Source is the source that the object is uploaded with.
+	Source string
+	// Unit is passed to OC as a X-Imid-Unit HTTP header.
+	Unit string
+	// Batch marks the upload as a batch upload.
+	Batch bool
+	// IfMatch causes the import to fail unless the object in OC
+	// has a matching ETag.
+	IfMatch string
+}
+
+// ImportResponse is the result of an import.
+type ImportResponse struct {
+	UploadResponse
+
+	// Manifest is the manifest of the imported bundle.
+	Manifest *BundleManifest
+}
+
+// ImportObject uploads a bundle created by ExportObject. The files are
+// buffered to temporary files as the upload needs all of them at
+// once.
+func (c *Client) ImportObject(
+	ctx context.Context, r io.Reader, opts *ImportOptions,
+) (*ImportResponse, error) {
+	var options ImportOptions
+
+	if opts != nil {
+		options = *opts
+	}
+
+	tr := tar.NewReader(r)
+
+	manifest, err := readBundleManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	spooled := make(map[string]*os.File)
+
+	defer func() {
+		for _, f := range spooled {
+			removeTemp(c, f)
+		}
+	}()
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		name := path.Base(hdr.Name)
+
+		if hdr.Typeflag != tar.TypeReg || path.Dir(hdr.Name)+"/" != bundleFileDir {
+			continue
+		}
+
+		tmp, err := spoolToTemp(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to buffer file %q: %w", name, err)
+		}
+
+		spooled[name] = tmp
+	}
+
+	files := make(FileSet)
+
+	for _, f := range manifest.Files {
+		tmp, ok := spooled[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("the bundle is missing the file %q", f.Name)
+		}
+
+		files[f.Field] = File{
+			Name:     f.Name,
+			Reader:   tmp,
+			Mimetype: f.Mimetype,
+		}
+	}
+
+	uuid := manifest.UUID
+	if options.UUID != "" {
+		uuid = options.UUID
+	}
+
+	res, err := c.Upload(ctx, UploadRequest{
+		UUID:    uuid,
+		Source:  options.Source,
+		Files:   files,
+		Unit:    options.Unit,
+		Batch:   options.Batch,
+		IfMatch: options.IfMatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload bundle: %w", err)
+	}
+
+	return &ImportResponse{
+		UploadResponse: *res,
+		Manifest:       manifest,
+	}, nil
+}
+
+func readBundleManifest(tr *tar.Reader) (*BundleManifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if hdr.Name != BundleManifestName {
+		return nil, fmt.Errorf(
+			"expected the bundle to start with %s, got %q",
+			BundleManifestName, hdr.Name)
+	}
+
+	var manifest BundleManifest
+
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	if manifest.UUID == "" || len(manifest.Files) == 0 {
+		return nil, errors.New("invalid manifest, a UUID and files are required")
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,136 @@
+package oc_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestClient_ExportObject(t *testing.T) {
+	source, sourceClient := newFakeOC(t)
+	target, targetClient := newFakeOC(t)
+
+	v1 := testImageVersion("image v1", "<newsItem>v1</newsItem>")
+	v1.ContentType = "Image"
+	v1.Preview = "preview.jpeg"
+	v1.Files["preview.jpeg"] = fakeFile{Mimetype: "image/jpeg", Data: []byte("preview")}
+	v1.Properties = []oc.Property{stringProp("Headline", "A sample")}
+
+	source.AddVersion(testImageUUID, v1)
+	source.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+
+	var bundle bytes.Buffer
+
+	manifest, err := sourceClient.ExportObject(context.Background(), testImageUUID, 1, &bundle)
+	if err != nil {
+		t.Fatalf("failed to export object: %v", err)
+	}
+
+	if manifest.Version != 1 || manifest.ETag != v1.etag() || manifest.ContentType != "Image" {
+		t.Errorf("unexpected manifest version %d, ETag %q and content type %q",
+			manifest.Version, manifest.ETag, manifest.ContentType)
+	}
+
+	var names []string
+
+	tr := tar.NewReader(bytes.NewReader(bundle.Bytes()))
+
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+
+		names = append(names, hdr.Name)
+	}
+
+	wantNames := []string{
+		"manifest.json",
+		"files/sample.jpeg",
+		"files/sample-image.metadata.xml",
+		"files/preview.jpeg",
+	}
+
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Errorf("unexpected bundle entries (-want +got):\n%s", diff)
+	}
+
+	res, err := targetClient.ImportObject(context.Background(), &bundle, &oc.ImportOptions{
+		Source: "bundle-test",
+	})
+	if err != nil {
+		t.Fatalf("failed to import bundle: %v", err)
+	}
+
+	if res.UUID != testImageUUID || res.Version != 1 {
+		t.Errorf("unexpected import result %s version %d", res.UUID, res.Version)
+	}
+
+	imported, _ := target.Version(testImageUUID, 0)
+
+	if imported.etag() != v1.etag() {
+		t.Error("expected the imported object to have the same files as the exported version")
+	}
+
+	if imported.Preview != "preview.jpeg" || imported.Source != "bundle-test" {
+		t.Errorf("unexpected preview %q and source %q", imported.Preview, imported.Source)
+	}
+}
+
+func TestClient_ExportObject__PrimaryInMetadata(t *testing.T) {
+	source, sourceClient := newFakeOC(t)
+	target, targetClient := newFakeOC(t)
+
+	v1 := testImageVersion("image v1", "<newsItem>v1</newsItem>")
+	v1.Metadata = append([]string{v1.Primary}, v1.Metadata...)
+
+	source.AddVersion(testImageUUID, v1)
+
+	var bundle bytes.Buffer
+
+	manifest, err := sourceClient.ExportObject(context.Background(), testImageUUID, 1, &bundle)
+	if err != nil {
+		t.Fatalf("failed to export object: %v", err)
+	}
+
+	var fields []string
+
+	for _, f := range manifest.Files {
+		fields = append(fields, f.Field)
+	}
+
+	if diff := cmp.Diff([]string{"file", "metadata"}, fields); diff != "" {
+		t.Errorf("unexpected bundle fields (-want +got):\n%s", diff)
+	}
+
+	if _, err := targetClient.ImportObject(context.Background(), &bundle, nil); err != nil {
+		t.Fatalf("failed to import bundle: %v", err)
+	}
+
+	imported, _ := target.Version(testImageUUID, 0)
+
+	if diff := cmp.Diff([]string{"sample-image.metadata.xml"}, imported.Metadata); diff != "" {
+		t.Errorf("unexpected imported metadata (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_ImportObject__BadBundle(t *testing.T) {
+	_, client := newFakeOC(t)
+
+	var bundle bytes.Buffer
+
+	tw := tar.NewWriter(&bundle)
+
+	_ = tw.WriteHeader(&tar.Header{Name: "files/sample.jpeg", Mode: 0o644, Size: 1})
+	_, _ = tw.Write([]byte("x"))
+	_ = tw.Close()
+
+	_, err := client.ImportObject(context.Background(), &bundle, nil)
+	if err == nil {
+		t.Fatal("expected a bundle without a manifest to be rejected")
+	}
+}
@@ -112,6 +112,12 @@ func fetchWithNoneMatch(etag string) fetchOption {
 	}
 }
 
+func fetchWithHeader(name, value string) fetchOption {
+	return func(req *http.Request, _ *requestInfo) {
+		req.Header.Set(name, value)
+	}
+}
+
 func fetchWithResourceName(name string) fetchOption {
 	return func(_ *http.Request, info *requestInfo) {
 		info.mainResource = name
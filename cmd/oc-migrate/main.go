@@ -0,0 +1,223 @@
+// Command oc-migrate copies objects between Open Content instances.
+//
+// Objects are selected on the source instance using a search query
+// and/or created and updated date ranges, exported with all their
+// files and imported into the target instance with their UUIDs
+// preserved. Progress is saved to a state file so that an
+// interrupted migration can be resumed by running the same command
+// again.
+//
+// Usage:
+//
+//	oc-migrate -source-url https://stage:8443/opencontent \
+//	    -target-url https://prod:8443/opencontent \
+//	    -query 'contenttype:Image' -updated-from 2024-01-01 \
+//	    -state migration.json -report report.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type clientFlags struct {
+	URL      string
+	Username string
+	Password string
+	Token    string
+}
+
+func (cf *clientFlags) register(fs *flag.FlagSet, prefix string, envPrefix string) {
+	fs.StringVar(&cf.URL, prefix+"-url", os.Getenv(envPrefix+"_BASEURL"),
+		"base URL of the "+prefix+" OC instance, defaults to $"+envPrefix+"_BASEURL")
+	fs.StringVar(&cf.Username, prefix+"-username", os.Getenv(envPrefix+"_USERNAME"),
+		"username for the "+prefix+" OC instance, defaults to $"+envPrefix+"_USERNAME")
+	fs.StringVar(&cf.Password, prefix+"-password", os.Getenv(envPrefix+"_PASSWORD"),
+		"password for the "+prefix+" OC instance, defaults to $"+envPrefix+"_PASSWORD")
+	fs.StringVar(&cf.Token, prefix+"-token", os.Getenv(envPrefix+"_TOKEN"),
+		"bearer token for the "+prefix+" OC instance, defaults to $"+envPrefix+"_TOKEN")
+}
+
+func (cf *clientFlags) client(name string) (*oc.Client, error) {
+	if cf.URL == "" {
+		return nil, fmt.Errorf("a %s URL is required", name)
+	}
+
+	opts := oc.Options{
+		BaseURL: cf.URL,
+	}
+
+	switch {
+	case cf.Token != "":
+		opts.Auth = oc.BearerAuth(cf.Token)
+	case cf.Username != "":
+		opts.Auth = oc.BasicAuth(cf.Username, cf.Password)
+	}
+
+	client, err := oc.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client: %w", name, err)
+	}
+
+	return client, nil
+}
+
+func run() error {
+	var (
+		source, target clientFlags
+		cfg            Config
+		createdFrom    string
+		createdTo      string
+		updatedFrom    string
+		updatedTo      string
+		reportPath     string
+	)
+
+	fs := flag.NewFlagSet("oc-migrate", flag.ExitOnError)
+
+	source.register(fs, "source", "OC_SOURCE")
+	target.register(fs, "target", "OC_TARGET")
+
+	fs.StringVar(&cfg.Query, "query", "", "search query selecting the objects to migrate")
+	fs.StringVar(&cfg.ContentType, "content-type", "", "only migrate objects of this content type")
+	fs.StringVar(&createdFrom, "created-from", "", "only migrate objects created at or after this date")
+	fs.StringVar(&createdTo, "created-to", "", "only migrate objects created before this date")
+	fs.StringVar(&updatedFrom, "updated-from", "", "only migrate objects updated at or after this date")
+	fs.StringVar(&updatedTo, "updated-to", "", "only migrate objects updated before this date")
+	fs.IntVar(&cfg.Concurrency, "concurrency", 4, "number of objects to migrate in parallel")
+	fs.StringVar(&cfg.StatePath, "state", "oc-migrate-state.json",
+		"file that progress is saved to, used to resume interrupted migrations")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "list the objects that would be migrated without migrating them")
+	fs.StringVar(&cfg.Source, "upload-source", "oc-migrate", "source to upload objects to the target with")
+	fs.StringVar(&cfg.Unit, "unit", "", "unit to upload objects to the target with")
+	fs.StringVar(&reportPath, "report", "", "write a JSON report to this file")
+
+	_ = fs.Parse(os.Args[1:])
+
+	var err error
+
+	cfg.Created, err = parseDateRange(createdFrom, createdTo)
+	if err != nil {
+		return fmt.Errorf("invalid created range: %w", err)
+	}
+
+	cfg.Updated, err = parseDateRange(updatedFrom, updatedTo)
+	if err != nil {
+		return fmt.Errorf("invalid updated range: %w", err)
+	}
+
+	if cfg.Query == "" && cfg.ContentType == "" &&
+		cfg.Created.Start.Date == nil && cfg.Created.End.Date == nil &&
+		cfg.Updated.Start.Date == nil && cfg.Updated.End.Date == nil {
+		return errors.New("refusing to migrate everything, use -query '*:*' if that is what you want")
+	}
+
+	sourceClient, err := source.client("source")
+	if err != nil {
+		return err
+	}
+
+	var targetClient *oc.Client
+
+	if !cfg.DryRun {
+		targetClient, err = target.client("target")
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	m := Migrator{
+		Source: sourceClient,
+		Target: targetClient,
+		Config: cfg,
+		Logger: log.Default(),
+	}
+
+	report, err := m.Run(ctx)
+	if report != nil {
+		log.Printf("%d selected, %d migrated, %d already done, %d failed",
+			report.Selected, report.Migrated, report.Skipped, len(report.Failures))
+
+		if reportPath != "" {
+			if err := writeReport(reportPath, report); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(report.Failures) > 0 {
+		return fmt.Errorf("failed to migrate %d objects", len(report.Failures))
+	}
+
+	return nil
+}
+
+func parseDateRange(from, to string) (oc.DateRange, error) {
+	var r oc.DateRange
+
+	if from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return r, err
+		}
+
+		r.Start = oc.DateBoundary{Type: oc.Inclusive, Date: &t}
+	}
+
+	if to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return r, err
+		}
+
+		r.End = oc.DateBoundary{Type: oc.Exclusive, Date: &t}
+	}
+
+	return r, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither a RFC3339 timestamp nor a date", value)
+}
+
+func writeReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+const searchPageSize = 100
+
+// SourceOC is the part of the OC client that is used against the
+// source instance.
+type SourceOC interface {
+	Search(ctx context.Context, req oc.SearchRequest) (*oc.SearchResponse, error)
+	ExportObject(ctx context.Context, uuid string, version int64, w io.Writer) (*oc.BundleManifest, error)
+}
+
+// TargetOC is the part of the OC client that is used against the
+// target instance.
+type TargetOC interface {
+	ImportObject(ctx context.Context, r io.Reader, opts *oc.ImportOptions) (*oc.ImportResponse, error)
+}
+
+// Config selects the objects to migrate and controls how they're
+// migrated.
+type Config struct {
+	Query       string
+	ContentType string
+	Created     oc.DateRange
+	Updated     oc.DateRange
+	Concurrency int
+	StatePath   string
+	DryRun      bool
+	Source      string
+	Unit        string
+}
+
+// Logger is used to log migration progress.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Migrator copies objects from one OC instance to another.
+type Migrator struct {
+	Source SourceOC
+	Target TargetOC
+	Config Config
+	Logger Logger
+}
+
+// Report summarises a migration run.
+type Report struct {
+	Started  time.Time       `json:"started"`
+	Finished time.Time       `json:"finished"`
+	DryRun   bool            `json:"dryRun"`
+	Selected int             `json:"selected"`
+	Migrated int             `json:"migrated"`
+	Skipped  int             `json:"skipped"`
+	Objects  []ObjectResult  `json:"objects"`
+	Failures []ObjectFailure `json:"failures"`
+}
+
+// ObjectResult is the outcome of a migrated object.
+type ObjectResult struct {
+	UUID          string `json:"uuid"`
+	SourceVersion int64  `json:"sourceVersion"`
+	TargetVersion int64  `json:"targetVersion,omitempty"`
+}
+
+// ObjectFailure is an object that couldn't be migrated.
+type ObjectFailure struct {
+	UUID  string `json:"uuid"`
+	Error string `json:"error"`
+}
+
+// Run performs the migration. Objects that were migrated by an
+// earlier run with the same state file are skipped.
+func (m *Migrator) Run(ctx context.Context) (*Report, error) {
+	report := Report{
+		Started: time.Now(),
+		DryRun:  m.Config.DryRun,
+	}
+
+	state, err := LoadState(m.Config.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	uuids, err := m.selectObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Selected = len(uuids)
+
+	var pending []string
+
+	for _, uuid := range uuids {
+		if state.Done(uuid) {
+			report.Skipped++
+
+			continue
+		}
+
+		pending = append(pending, uuid)
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+
+	concurrency := max(m.Config.Concurrency, 1)
+
+	for range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for uuid := range jobs {
+				result, err := m.migrate(ctx, uuid)
+
+				mu.Lock()
+
+				if err != nil {
+					m.Logger.Printf("failed to migrate %s: %v", uuid, err)
+
+					report.Failures = append(report.Failures, ObjectFailure{
+						UUID: uuid, Error: err.Error(),
+					})
+				} else {
+					report.Objects = append(report.Objects, *result)
+
+					if !m.Config.DryRun {
+						report.Migrated++
+
+						if err := state.MarkDone(*result); err != nil {
+							m.Logger.Printf("failed to record state: %v", err)
+						}
+					}
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, uuid := range pending {
+		select {
+		case jobs <- uuid:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if !m.Config.DryRun {
+		if err := state.Save(); err != nil {
+			m.Logger.Printf("failed to save state: %v", err)
+		}
+	}
+
+	report.Finished = time.Now()
+
+	if ctx.Err() != nil {
+		return &report, fmt.Errorf("migration interrupted: %w", ctx.Err())
+	}
+
+	return &report, nil
+}
+
+func (m *Migrator) selectObjects(ctx context.Context) ([]string, error) {
+	var uuids []string
+
+	req := oc.SearchRequest{
+		Query:       m.Config.Query,
+		ContentType: m.Config.ContentType,
+		Created:     m.Config.Created,
+		Updated:     m.Config.Updated,
+		Properties:  "uuid",
+		Limit:       searchPageSize,
+		Sort: []oc.SearchSort{{
+			IndexField: "created",
+		}},
+	}
+
+	for {
+		res, err := m.Source.Search(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search source: %w", err)
+		}
+
+		for _, hit := range res.Hits.Items {
+			uuids = append(uuids, hit.ID)
+		}
+
+		req.Start += len(res.Hits.Items)
+
+		if len(res.Hits.Items) == 0 || req.Start >= res.Hits.TotalHits {
+			return uuids, nil
+		}
+	}
+}
+
+func (m *Migrator) migrate(ctx context.Context, uuid string) (*ObjectResult, error) {
+	if m.Config.DryRun {
+		m.Logger.Printf("would migrate %s", uuid)
+
+		return &ObjectResult{UUID: uuid}, nil
+	}
+
+	pr, pw := io.Pipe()
+
+	exported := make(chan *oc.BundleManifest, 1)
+	exportErr := make(chan error, 1)
+
+	go func() {
+		manifest, err := m.Source.ExportObject(ctx, uuid, 0, pw)
+
+		_ = pw.CloseWithError(err)
+
+		exported <- manifest
+		exportErr <- err
+	}()
+
+	res, importErr := m.Target.ImportObject(ctx, pr, &oc.ImportOptions{
+		Source: m.Config.Source,
+		Unit:   m.Config.Unit,
+		Batch:  true,
+	})
+
+	// Unblock the export if the import bailed out early.
+	_ = pr.CloseWithError(errors.New("import finished"))
+
+	manifest := <-exported
+
+	if err := <-exportErr; err != nil {
+		return nil, fmt.Errorf("failed to export: %w", err)
+	}
+
+	if importErr != nil {
+		return nil, fmt.Errorf("failed to import: %w", importErr)
+	}
+
+	m.Logger.Printf("migrated %s version %d as version %d",
+		uuid, manifest.Version, res.Version)
+
+	return &ObjectResult{
+		UUID:          uuid,
+		SourceVersion: manifest.Version,
+		TargetVersion: res.Version,
+	}, nil
+}
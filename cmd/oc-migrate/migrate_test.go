@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+type stubSource struct {
+	uuids []string
+	fail  map[string]bool
+}
+
+func (s *stubSource) Search(_ context.Context, req oc.SearchRequest) (*oc.SearchResponse, error) {
+	var res oc.SearchResponse
+
+	res.Hits.TotalHits = len(s.uuids)
+
+	end := min(req.Start+req.Limit, len(s.uuids))
+
+	for _, uuid := range s.uuids[req.Start:end] {
+		res.Hits.Items = append(res.Hits.Items, oc.Hit{ID: uuid})
+	}
+
+	return &res, nil
+}
+
+func (s *stubSource) ExportObject(
+	_ context.Context, uuid string, _ int64, w io.Writer,
+) (*oc.BundleManifest, error) {
+	if s.fail[uuid] {
+		return nil, errors.New("export failed")
+	}
+
+	_, err := io.WriteString(w, "bundle:"+uuid)
+	if err != nil {
+		return nil, fmt.Errorf("write: %w", err)
+	}
+
+	return &oc.BundleManifest{UUID: uuid, Version: 3}, nil
+}
+
+type stubTarget struct {
+	m        sync.Mutex
+	imported map[string]string
+}
+
+func (t *stubTarget) ImportObject(
+	_ context.Context, r io.Reader, _ *oc.ImportOptions,
+) (*oc.ImportResponse, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	uuid := string(data[len("bundle:"):])
+
+	t.imported[uuid] = string(data)
+
+	return &oc.ImportResponse{
+		UploadResponse: oc.UploadResponse{UUID: uuid, Version: 1},
+	}, nil
+}
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (l testLogger) Printf(format string, v ...interface{}) {
+	l.t.Logf(format, v...)
+}
+
+func TestMigrator_Run(t *testing.T) {
+	var uuids []string
+
+	for i := range 250 {
+		uuids = append(uuids, fmt.Sprintf("00000000-0000-0000-0000-%012d", i))
+	}
+
+	source := &stubSource{
+		uuids: uuids,
+		fail:  map[string]bool{uuids[7]: true},
+	}
+	target := &stubTarget{imported: make(map[string]string)}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	m := Migrator{
+		Source: source,
+		Target: target,
+		Logger: testLogger{t},
+		Config: Config{
+			Query:       "*:*",
+			Concurrency: 8,
+			StatePath:   statePath,
+		},
+	}
+
+	report, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	if report.Selected != 250 || report.Migrated != 249 || len(report.Failures) != 1 {
+		t.Fatalf("unexpected report: %d selected, %d migrated, %d failed",
+			report.Selected, report.Migrated, len(report.Failures))
+	}
+
+	if len(target.imported) != 249 {
+		t.Errorf("expected 249 imported objects, got %d", len(target.imported))
+	}
+
+	// A second run should only retry the failed object.
+	delete(source.fail, uuids[7])
+
+	report, err = m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("second migration failed: %v", err)
+	}
+
+	if report.Skipped != 249 || report.Migrated != 1 {
+		t.Errorf("expected the second run to skip 249 and migrate 1, got %d and %d",
+			report.Skipped, report.Migrated)
+	}
+}
+
+func TestMigrator_Run__DryRun(t *testing.T) {
+	source := &stubSource{uuids: []string{"a", "b"}}
+
+	m := Migrator{
+		Source: source,
+		Logger: testLogger{t},
+		Config: Config{
+			Query:  "*:*",
+			DryRun: true,
+		},
+	}
+
+	report, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+
+	if report.Selected != 2 || report.Migrated != 0 || len(report.Objects) != 2 {
+		t.Errorf("unexpected dry run report: %+v", report)
+	}
+}
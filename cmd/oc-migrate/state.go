@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// State keeps track of the objects that have been migrated. Migrated
+// objects are appended to a journal next to the state file as they
+// are done, and Save folds the journal into the state file.
+type State struct {
+	path    string
+	journal *os.File
+	Objects map[string]ObjectResult `json:"objects"`
+}
+
+// journalPath returns the path of the journal for a state file.
+func journalPath(path string) string {
+	return path + ".journal"
+}
+
+// LoadState loads the migration state from path, together with any
+// objects recorded in its journal. A missing file results in an
+// empty state.
+func LoadState(path string) (*State, error) {
+	state := State{
+		path:    path,
+		Objects: make(map[string]ObjectResult),
+	}
+
+	if path == "" {
+		return &state, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse state %q: %w", path, err)
+		}
+	}
+
+	if state.Objects == nil {
+		state.Objects = make(map[string]ObjectResult)
+	}
+
+	if err := state.replayJournal(); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// replayJournal adds the objects in the journal to the state. A
+// partially written last entry, left by an interrupted run, is
+// ignored.
+func (s *State) replayJournal() error {
+	data, err := os.ReadFile(journalPath(s.path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read state journal: %w", err)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		var result ObjectResult
+
+		err := json.Unmarshal(line, &result)
+		if err != nil && i == len(lines)-1 {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to parse entry %d of the state journal: %w", i+1, err)
+		}
+
+		s.Objects[result.UUID] = result
+	}
+
+	return nil
+}
+
+// Done returns true if the object has been migrated.
+func (s *State) Done(uuid string) bool {
+	_, ok := s.Objects[uuid]
+
+	return ok
+}
+
+// MarkDone records an object as migrated and appends it to the
+// journal.
+func (s *State) MarkDone(result ObjectResult) error {
+	s.Objects[result.UUID] = result
+
+	if s.path == "" {
+		return nil
+	}
+
+	if s.journal == nil {
+		f, err := os.OpenFile(journalPath(s.path),
+			os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open state journal: %w", err)
+		}
+
+		s.journal = f
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state journal entry: %w", err)
+	}
+
+	if _, err := s.journal.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to state journal: %w", err)
+	}
+
+	return nil
+}
+
+// Save writes the state to disk, replacing the old state atomically,
+// and removes the journal.
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	if s.journal != nil {
+		_ = s.journal.Close()
+		s.journal = nil
+	}
+
+	err = os.Remove(journalPath(s.path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove state journal: %w", err)
+	}
+
+	return nil
+}
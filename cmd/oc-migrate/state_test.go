@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestState_Journal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("failed to load empty state: %v", err)
+	}
+
+	for _, uuid := range []string{"a", "b"} {
+		if err := state.MarkDone(ObjectResult{UUID: uuid, SourceVersion: 1}); err != nil {
+			t.Fatalf("failed to mark %s as done: %v", uuid, err)
+		}
+	}
+
+	// Simulate a run that was killed while writing an entry.
+	f, err := os.OpenFile(path+".journal", os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	_, _ = f.WriteString(`{"uuid":"c","sourceVe`)
+	_ = f.Close()
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("failed to load state from journal: %v", err)
+	}
+
+	if !loaded.Done("a") || !loaded.Done("b") || loaded.Done("c") {
+		t.Errorf("unexpected objects in state: %v", loaded.Objects)
+	}
+
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	if _, err := os.Stat(path + ".journal"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the journal to be removed, got: %v", err)
+	}
+
+	saved, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("failed to load saved state: %v", err)
+	}
+
+	if len(saved.Objects) != 2 {
+		t.Errorf("expected two objects in the saved state, got %v", saved.Objects)
+	}
+}
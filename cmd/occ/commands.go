@@ -0,0 +1,645 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func cmdGet(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "get")
+
+	properties := fs.String("properties", "uuid,contenttype,updated", "properties to return")
+	deleted := fs.Bool("deleted", false, "include deleted objects")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	res, err := a.client.Get(ctx, oc.GetRequest{
+		UUIDs:      fs.Args(),
+		Properties: *properties,
+		Deleted:    *deleted,
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		hitsTable(t, res.Hits, *properties)
+	})
+}
+
+func hitsTable(t *table, hits oc.Hits, properties string) {
+	var names []string
+
+	for _, name := range strings.Split(properties, ",") {
+		// Nested properties can't be shown in a table.
+		if name != "" && !strings.ContainsAny(name, "[]") {
+			names = append(names, name)
+		}
+	}
+
+	t.Header = append([]string{"ID", "VERSION"}, names...)
+
+	for _, hit := range hits.Items {
+		row := []interface{}{hit.ID, hit.Version}
+
+		for _, name := range names {
+			values, _ := hit.Properties.GetValues(name)
+
+			row = append(row, strings.Join(values, ", "))
+		}
+
+		t.add(row...)
+	}
+}
+
+func cmdExists(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "exists")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	res, err := a.client.CheckExists(ctx, fs.Arg(0))
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"EXISTS", "VERSION", "ETAG", "CONTENT TYPE", "LENGTH"}
+		t.add(res.Exists, res.Version, res.ETag, res.ContentType, res.ContentLength)
+	})
+}
+
+func cmdFiles(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "files")
+
+	version := fs.Int64("version", 0, "object version, defaults to the current version")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	res, err := a.client.ListFiles(ctx, fs.Arg(0), *version)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"KIND", "NAME", "MIMETYPE"}
+
+		add := func(kind string, f oc.ObjectFile) {
+			if f.Name != "" {
+				t.add(kind, f.Name, f.Mimetype)
+			}
+		}
+
+		add("primary", res.Primary)
+
+		for _, f := range res.Metadata {
+			add("metadata", f)
+		}
+
+		add("preview", res.Preview)
+		add("thumb", res.Thumb)
+	})
+}
+
+func cmdDownload(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "download")
+
+	version := fs.Int64("version", 0, "object version, defaults to the current version")
+	dst := fs.String("dst", "", "destination path, defaults to the filename")
+	quiet := fs.Bool("quiet", false, "don't report progress")
+
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	filename := fs.Arg(1)
+
+	if *dst == "" {
+		*dst = filepath.Base(filename)
+	}
+
+	opts := oc.DownloadOptions{}
+
+	if !*quiet {
+		var last time.Time
+
+		opts.Progress = func(written, total int64) {
+			if time.Since(last) < 500*time.Millisecond && written != total {
+				return
+			}
+
+			last = time.Now()
+
+			if total > 0 {
+				fmt.Fprintf(a.stderr, "\r%s: %d/%d bytes (%d%%)",
+					filename, written, total, written*100/total)
+			} else {
+				fmt.Fprintf(a.stderr, "\r%s: %d bytes", filename, written)
+			}
+		}
+	}
+
+	res, err := a.client.DownloadFile(ctx, fs.Arg(0), filename, *version, *dst, &opts)
+
+	if !*quiet {
+		fmt.Fprintln(a.stderr)
+	}
+
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"PATH", "SIZE", "VERSION", "ETAG", "RESUMES"}
+		t.add(*dst, res.Size, res.Version, res.ETag, res.Resumes)
+	})
+}
+
+func cmdUpload(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "upload")
+
+	req := oc.UploadRequest{
+		Files: make(oc.FileSet),
+	}
+
+	fs.StringVar(&req.UUID, "uuid", "", "UUID of the object, generated by OC if not set")
+	fs.StringVar(&req.Source, "source", "occ", "upload source")
+	fs.StringVar(&req.Unit, "unit", "", "unit to upload as")
+	fs.BoolVar(&req.Batch, "batch", false, "perform a batch upload")
+	fs.StringVar(&req.IfMatch, "if-match", "", "only upload if the object has this ETag")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	for _, spec := range fs.Args() {
+		field, file, err := parseFileSpec(spec)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(file.Name)
+		if err != nil {
+			return fmt.Errorf("failed to open %q: %w", file.Name, err)
+		}
+
+		defer f.Close() //nolint:errcheck
+
+		file.Reader = f
+		file.Name = filepath.Base(file.Name)
+
+		req.Files[field] = file
+	}
+
+	res, err := a.client.Upload(ctx, req)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"UUID", "VERSION", "ETAG"}
+		t.add(res.UUID, res.Version, res.ETag)
+	})
+}
+
+// parseFileSpec parses an upload file argument in the form
+// field=path[:mimetype]. The path is only split at its last colon if
+// what follows looks like a mimetype, so paths can contain colons.
+// The mimetype is guessed from the file extension if it's left out.
+func parseFileSpec(spec string) (string, oc.File, error) {
+	field, rest, ok := strings.Cut(spec, "=")
+	if !ok || field == "" || rest == "" {
+		return "", oc.File{}, fmt.Errorf(
+			"invalid file %q, expected field=path[:mimetype]", spec)
+	}
+
+	path, mimetype := rest, ""
+
+	if i := strings.LastIndex(rest, ":"); i != -1 && isMimetype(rest[i+1:]) {
+		path, mimetype = rest[:i], rest[i+1:]
+	}
+
+	if mimetype == "" {
+		mimetype = mime.TypeByExtension(filepath.Ext(path))
+	}
+
+	if mimetype == "" {
+		return "", oc.File{}, fmt.Errorf(
+			"could not guess the mimetype of %q, specify it as field=path:mimetype", path)
+	}
+
+	return field, oc.File{Name: path, Mimetype: mimetype}, nil
+}
+
+// mediaTypes are the registered top level media types.
+var mediaTypes = map[string]bool{
+	"application": true, "audio": true, "font": true, "image": true,
+	"message": true, "model": true, "multipart": true, "text": true,
+	"video": true,
+}
+
+// isMimetype reports whether s looks like a type/subtype mimetype.
+func isMimetype(s string) bool {
+	mediaType, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		return false
+	}
+
+	typ, subtype, ok := strings.Cut(mediaType, "/")
+
+	return ok && mediaTypes[typ] && subtype != "" && !strings.Contains(subtype, "/")
+}
+
+func cmdDelete(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "delete")
+
+	var opts oc.DeleteOptions
+
+	fs.StringVar(&opts.IfMatch, "if-match", "", "only delete if the object has this ETag")
+	fs.StringVar(&opts.Unit, "unit", "", "unit to delete as")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	return a.client.Delete(ctx, fs.Arg(0), &opts) //nolint:wrapcheck
+}
+
+func cmdPurge(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "purge")
+
+	var opts oc.PurgeOptions
+
+	fs.StringVar(&opts.IfMatch, "if-match", "", "only purge if the object has this ETag")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	return a.client.Purge(ctx, fs.Arg(0), &opts) //nolint:wrapcheck
+}
+
+func cmdUndelete(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "undelete")
+
+	var opts oc.UndeleteOptions
+
+	fs.StringVar(&opts.Unit, "unit", "", "unit to undelete as")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	return a.client.Undelete(ctx, fs.Arg(0), &opts) //nolint:wrapcheck
+}
+
+func cmdProperties(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "properties")
+
+	version := fs.Int64("version", 0, "object version, defaults to the current version")
+
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	var list oc.PropertyList
+
+	if err := list.UnmarshalText([]byte(fs.Arg(1))); err != nil {
+		return fmt.Errorf("invalid property list: %w", err)
+	}
+
+	var (
+		res *oc.PropertyResult
+		err error
+	)
+
+	if *version != 0 {
+		res, err = a.client.PropertiesVersion(ctx, fs.Arg(0), *version, list)
+	} else {
+		res, err = a.client.Properties(ctx, fs.Arg(0), list)
+	}
+
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"PROPERTY", "TYPE", "VALUE"}
+
+		propertiesTable(t, "", res.Properties)
+	})
+}
+
+func propertiesTable(t *table, prefix string, props []oc.Property) {
+	for _, p := range props {
+		for i, v := range p.Values {
+			if v.NestedProperty != nil {
+				propertiesTable(t,
+					fmt.Sprintf("%s%s[%d].", prefix, p.Name, i),
+					v.NestedProperty.Properties)
+
+				continue
+			}
+
+			t.add(prefix+p.Name, p.Type, v.Value)
+		}
+	}
+}
+
+func cmdSearch(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "search")
+
+	req := oc.SearchRequest{}
+
+	var sorts stringList
+
+	fs.StringVar(&req.Query, "q", "", "search query")
+	fs.StringVar(&req.ContentType, "content-type", "", "content type to search for")
+	fs.StringVar(&req.Properties, "properties", "uuid,contenttype,updated", "properties to return")
+	fs.StringVar(&req.FilterQuery, "fq", "", "filter query")
+	fs.IntVar(&req.Start, "start", 0, "offset of the first hit")
+	fs.IntVar(&req.Limit, "limit", 15, "number of hits to return")
+	fs.Var(&sorts, "sort", "index field to sort on, append :desc for descending order, repeatable")
+
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	for _, s := range sorts {
+		field, order, _ := strings.Cut(s, ":")
+
+		req.Sort = append(req.Sort, oc.SearchSort{
+			IndexField: field,
+			Descending: order == "desc",
+		})
+	}
+
+	res, err := a.client.Search(ctx, req)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if a.format == formatTable {
+		fmt.Fprintf(a.stderr, "%d hits\n", res.Hits.TotalHits)
+	}
+
+	return a.printResult(res, func(t *table) {
+		hitsTable(t, res.Hits, req.Properties)
+	})
+}
+
+func cmdSuggest(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "suggest")
+
+	req := oc.SuggestRequest{}
+
+	suggestType := fs.String("type", "facet", "suggest type, facet or ngram")
+	incomplete := fs.String("incomplete", "", "incomplete word to suggest completions for")
+
+	fs.StringVar(&req.Query, "q", "", "query limiting the suggestions")
+	fs.IntVar(&req.Limit, "limit", 10, "number of suggestions per field")
+
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	switch *suggestType {
+	case "facet":
+		req.Type = oc.Facet
+	case "ngram":
+		req.Type = oc.Ngram
+	default:
+		return fmt.Errorf("unknown suggest type %q", *suggestType)
+	}
+
+	for _, field := range fs.Args() {
+		req.IndexFields = append(req.IndexFields, oc.SuggestIndexField{
+			Name:           field,
+			IncompleteWord: *incomplete,
+		})
+	}
+
+	res, err := a.client.Suggest(ctx, req)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"FIELD", "TERM", "FREQUENCY"}
+
+		for _, f := range res.Fields {
+			for _, term := range f.Terms {
+				t.add(f.Name, term.Name, term.Frequency)
+			}
+		}
+	})
+}
+
+func cmdContentTypes(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "contenttypes")
+
+	var req oc.ContentTypesRequest
+
+	fs.BoolVar(&req.Temporary, "temporary", false, "show the temporary configuration")
+
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	res, err := a.client.ContentTypes(ctx, req)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{
+			"CONTENT TYPE", "PROPERTY", "TYPE", "INDEX TYPE",
+			"MULTI", "SEARCHABLE", "SUGGEST", "READ ONLY",
+		}
+
+		for _, ct := range res.ContentTypes {
+			for _, p := range ct.Properties {
+				t.add(ct.Name, p.Name, p.TypeName(), p.IndexFieldTypeName(),
+					p.MultiValued, p.Searchable, p.Suggest, p.ReadOnly)
+			}
+		}
+	})
+}
+
+func cmdSchemaDiff(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "schemadiff")
+
+	breakingOnly := fs.Bool("breaking", false, "only show breaking changes")
+	failBreaking := fs.Bool("fail-breaking", false, "fail if there are breaking changes")
+
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	diffs, err := a.client.DiffTemporarySchema(ctx)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	var (
+		res      []oc.SchemaDifference
+		breaking int
+	)
+
+	for _, d := range diffs {
+		if d.Breaking {
+			breaking++
+		}
+
+		if d.Breaking || !*breakingOnly {
+			res = append(res, d)
+		}
+	}
+
+	err = a.printResult(res, func(t *table) {
+		t.Header = []string{
+			"CONTENT TYPE", "PROPERTY", "CHANGE", "ATTRIBUTE", "OLD", "NEW", "BREAKING",
+		}
+
+		for _, d := range res {
+			t.add(d.ContentType, d.Property, d.Type, d.Attribute, d.Old, d.New, d.Breaking)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if *failBreaking && breaking > 0 {
+		return fmt.Errorf("the temporary configuration has %d breaking changes", breaking)
+	}
+
+	return nil
+}
+
+func cmdHealth(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "health")
+
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	res, err := a.client.Health(ctx, oc.HealthRequest{})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(res, func(t *table) {
+		t.Header = []string{"CHECK", "VALUE"}
+		t.add("indexer", res.Indexer)
+		t.add("index", res.Solr)
+		t.add("database", res.Database)
+		t.add("filesystem", res.Storage)
+		t.add("free disk space", res.FreeSystemDiskSpace)
+		t.add("memory", fmt.Sprintf("%d/%d", res.CurrentMemory, res.MaximumMemory))
+		t.add("active configuration", res.ActiveConfiguration.Checksum)
+		t.add("temporary configuration", res.TempConfiguration.Checksum)
+	})
+}
+
+func cmdVersion(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "version")
+
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	version, err := a.client.GetVersion(ctx)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return a.printResult(map[string]string{"version": version}, func(t *table) {
+		t.add(version)
+	})
+}
+
+func cmdEventlog(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || args[0] != "tail" {
+		fmt.Fprintln(a.stderr, "usage: occ "+a.usage)
+
+		return errors.New("eventlog needs a subcommand")
+	}
+
+	fs := newFlagSet(a, "eventlog")
+
+	from := fs.Int("from", -1, "event ID to start after, defaults to only new events")
+	interval := fs.Duration("interval", 5*time.Second, "poll interval")
+
+	if err := parseArgs(fs, args[1:], 0); err != nil {
+		return err
+	}
+
+	last := *from
+
+	if last < 0 {
+		// OC returns the latest events when asked for a negative
+		// event ID.
+		events, err := a.client.Eventlog(ctx, -1)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		last = 0
+
+		for _, e := range events {
+			last = max(last, e.ID)
+		}
+	}
+
+	for {
+		events, err := a.client.Eventlog(ctx, last)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		for _, e := range events {
+			last = max(last, e.ID)
+
+			err := a.printEvent(e)
+			if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func (a *app) printEvent(e oc.EventlogEvent) error {
+	if a.format == formatJSON {
+		// JSON lines, so that the output can be piped to jq.
+		return json.NewEncoder(a.stdout).Encode(e) //nolint:wrapcheck
+	}
+
+	_, err := fmt.Fprintf(a.stdout, "%d\t%s\t%s\t%s\tv%d\t%s\n",
+		e.ID, e.Created.Format(time.RFC3339), e.EventType, e.UUID,
+		e.Content.Version, e.Content.ContentType)
+	if err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+// Config is the occ configuration file.
+type Config struct {
+	// DefaultProfile is used when no profile has been selected.
+	DefaultProfile string              `json:"defaultProfile"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// Profile holds the base URL and credentials for an OC instance.
+type Profile struct {
+	BaseURL  string `json:"baseURL"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// defaultConfigPath returns the path of the configuration file,
+// $OCC_CONFIG or occ/config.json in the user configuration directory.
+func defaultConfigPath() string {
+	if p := os.Getenv("OCC_CONFIG"); p != "" {
+		return p
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "occ", "config.json")
+}
+
+// LoadConfig reads the configuration file at path. A missing file
+// results in an empty configuration.
+func LoadConfig(path string) (*Config, error) {
+	cfg := Config{
+		Profiles: make(map[string]*Profile),
+	}
+
+	if path == "" {
+		return &cfg, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec
+	if errors.Is(err, os.ErrNotExist) {
+		return &cfg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration %q: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Profile resolves the profile to use. The environment variables
+// OC_BASEURL, OC_USERNAME, OC_PASSWORD and OC_TOKEN override the
+// values of the profile.
+func (cfg *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+
+	var p Profile
+
+	if name != "" {
+		named, ok := cfg.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+
+		p = *named
+	}
+
+	overrideFromEnv(&p.BaseURL, "OC_BASEURL")
+	overrideFromEnv(&p.Username, "OC_USERNAME")
+	overrideFromEnv(&p.Password, "OC_PASSWORD")
+	overrideFromEnv(&p.Token, "OC_TOKEN")
+
+	if p.BaseURL == "" {
+		return nil, errors.New(
+			"no base URL configured, select a profile or set OC_BASEURL")
+	}
+
+	return &p, nil
+}
+
+func overrideFromEnv(v *string, name string) {
+	if env := os.Getenv(name); env != "" {
+		*v = env
+	}
+}
+
+// Client creates an OC client for the profile.
+func (p *Profile) Client() (*oc.Client, error) {
+	opts := oc.Options{
+		BaseURL: p.BaseURL,
+	}
+
+	switch {
+	case p.Token != "":
+		opts.Auth = oc.BearerAuth(p.Token)
+	case p.Username != "":
+		opts.Auth = oc.BasicAuth(p.Username, p.Password)
+	}
+
+	client, err := oc.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,202 @@
+// Command occ is a command line client for Open Content.
+//
+// Base URLs and credentials are read from named profiles in
+// $OCC_CONFIG, or occ/config.json in the user configuration
+// directory:
+//
+//	{
+//	  "defaultProfile": "stage",
+//	  "profiles": {
+//	    "stage": {
+//	      "baseURL": "https://stage:8443/opencontent",
+//	      "username": "admin",
+//	      "password": "secret"
+//	    }
+//	  }
+//	}
+//
+// The OC_BASEURL, OC_USERNAME, OC_PASSWORD and OC_TOKEN environment
+// variables override the values of the selected profile.
+//
+// Usage:
+//
+//	occ [-profile name] [-format table|json] <command> [arguments]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+	format string
+	usage  string
+	client *oc.Client
+}
+
+type command struct {
+	Usage string
+	Run   func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"get":          {"get [-properties p] [-deleted] uuid...", cmdGet},
+	"head":         {"head uuid", cmdExists},
+	"exists":       {"exists uuid", cmdExists},
+	"files":        {"files [-version n] uuid", cmdFiles},
+	"download":     {"download [-version n] [-dst path] uuid filename", cmdDownload},
+	"upload":       {"upload [-uuid id] [-source s] [-unit u] [-batch] [-if-match etag] field=path[:mimetype]...", cmdUpload},
+	"delete":       {"delete [-if-match etag] [-unit u] uuid", cmdDelete},
+	"purge":        {"purge [-if-match etag] uuid", cmdPurge},
+	"undelete":     {"undelete [-unit u] uuid", cmdUndelete},
+	"properties":   {"properties [-version n] uuid properties", cmdProperties},
+	"search":       {"search [-q query] [-content-type t] [-properties p] [-start n] [-limit n] [-sort field[:desc]]", cmdSearch},
+	"suggest":      {"suggest [-type facet|ngram] [-q query] [-limit n] [-incomplete word] field...", cmdSuggest},
+	"contenttypes": {"contenttypes [-temporary]", cmdContentTypes},
+	"schemadiff":   {"schemadiff [-breaking] [-fail-breaking]", cmdSchemaDiff},
+	"health":       {"health", cmdHealth},
+	"version":      {"version", cmdVersion},
+	"eventlog":     {"eventlog tail [-from id] [-interval d]", cmdEventlog},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "occ:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("occ", flag.ContinueOnError)
+
+	fs.SetOutput(stderr)
+
+	profile := fs.String("profile", os.Getenv("OCC_PROFILE"), "configuration profile to use, defaults to $OCC_PROFILE")
+	configPath := fs.String("config", defaultConfigPath(), "path to the configuration file")
+	format := fs.String("format", formatTable, "output format, table or json")
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: occ [flags] <command> [arguments]")
+		fmt.Fprintln(stderr, "\nflags:")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr, "\ncommands:")
+
+		names := make([]string, 0, len(commands))
+
+		for name := range commands {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintln(stderr, "  "+commands[name].Usage)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if *format != formatTable && *format != formatJSON {
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return errors.New("no command given")
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	p, err := cfg.Profile(*profile)
+	if err != nil {
+		return err
+	}
+
+	client, err := p.Client()
+	if err != nil {
+		return err
+	}
+
+	a := app{
+		stdout: stdout,
+		stderr: stderr,
+		format: *format,
+		usage:  cmd.Usage,
+		client: client,
+	}
+
+	return cmd.Run(ctx, &a, fs.Args()[1:])
+}
+
+// newFlagSet creates a flag set for a command that prints the
+// command usage on errors.
+func newFlagSet(a *app, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "usage: occ "+a.usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseArgs parses the command flags and checks that it got at least
+// min positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, minArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if fs.NArg() < minArgs {
+		fs.Usage()
+
+		return fmt.Errorf("%s needs at least %d arguments", fs.Name(), minArgs)
+	}
+
+	return nil
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ",")
+}
+
+func (sl *stringList) Set(v string) error {
+	*sl = append(*sl, v)
+
+	return nil
+}
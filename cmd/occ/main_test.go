@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, cfg Config) string {
+	t.Helper()
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestConfig_Profile(t *testing.T) {
+	t.Setenv("OC_BASEURL", "")
+	t.Setenv("OC_USERNAME", "")
+	t.Setenv("OC_PASSWORD", "env-secret")
+	t.Setenv("OC_TOKEN", "")
+
+	cfg, err := LoadConfig(writeConfig(t, Config{
+		DefaultProfile: "stage",
+		Profiles: map[string]*Profile{
+			"stage": {BaseURL: "https://stage/opencontent", Username: "admin"},
+			"prod":  {BaseURL: "https://prod/opencontent"},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	p, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("failed to resolve default profile: %v", err)
+	}
+
+	if p.BaseURL != "https://stage/opencontent" || p.Password != "env-secret" {
+		t.Errorf("unexpected default profile: %+v", p)
+	}
+
+	if _, err := cfg.Profile("dev"); err == nil {
+		t.Error("expected an unknown profile to fail")
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/infoandstats/version" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if user, _, _ := r.BasicAuth(); user != "admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("3.2.1"))
+	}))
+
+	t.Cleanup(server.Close)
+
+	t.Setenv("OC_BASEURL", "")
+	t.Setenv("OC_USERNAME", "")
+	t.Setenv("OC_PASSWORD", "")
+	t.Setenv("OC_TOKEN", "")
+
+	config := writeConfig(t, Config{
+		Profiles: map[string]*Profile{
+			"test": {BaseURL: server.URL, Username: "admin", Password: "secret"},
+		},
+	})
+
+	var stdout, stderr bytes.Buffer
+
+	err := run(context.Background(),
+		[]string{"-config", config, "-profile", "test", "-format", "json", "version"},
+		&stdout, &stderr)
+	if err != nil {
+		t.Fatalf("failed to run version command: %v\n%s", err, stderr.String())
+	}
+
+	var got map[string]string
+
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	if got["version"] != "3.2.1" {
+		t.Errorf("unexpected version output: %q", stdout.String())
+	}
+
+	err = run(context.Background(),
+		[]string{"-config", config, "-profile", "test", "nope"},
+		&stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected an unknown command error, got: %v", err)
+	}
+}
+
+func TestParseFileSpec(t *testing.T) {
+	field, file, err := parseFileSpec("file=images/sample.jpeg")
+	if err != nil {
+		t.Fatalf("failed to parse file spec: %v", err)
+	}
+
+	if field != "file" || file.Name != "images/sample.jpeg" || file.Mimetype != "image/jpeg" {
+		t.Errorf("unexpected result %q %+v", field, file)
+	}
+
+	_, file, err = parseFileSpec("metadata=sample.xml:application/vnd.iptc.g2.newsitem+xml")
+	if err != nil {
+		t.Fatalf("failed to parse file spec: %v", err)
+	}
+
+	if file.Mimetype != "application/vnd.iptc.g2.newsitem+xml" {
+		t.Errorf("unexpected mimetype %q", file.Mimetype)
+	}
+
+	_, file, err = parseFileSpec("file=scans/2024-01-01T10:00:00.jpeg")
+	if err != nil {
+		t.Fatalf("failed to parse file spec: %v", err)
+	}
+
+	if file.Name != "scans/2024-01-01T10:00:00.jpeg" || file.Mimetype != "image/jpeg" {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	_, file, err = parseFileSpec("file=a:b/sample.xml:text/xml")
+	if err != nil {
+		t.Fatalf("failed to parse file spec: %v", err)
+	}
+
+	if file.Name != "a:b/sample.xml" || file.Mimetype != "text/xml" {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	for _, spec := range []string{"sample.jpeg", "file=", "file=sample.unknownext"} {
+		if _, _, err := parseFileSpec(spec); err == nil {
+			t.Errorf("expected %q to fail parsing", spec)
+		}
+	}
+}
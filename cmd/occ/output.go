@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// table is a simple tabular representation of a result.
+type table struct {
+	Header []string
+	Rows   [][]string
+}
+
+func (t *table) add(values ...interface{}) {
+	row := make([]string, len(values))
+
+	for i, v := range values {
+		row[i] = strings.ReplaceAll(fmt.Sprint(v), "\n", " ")
+	}
+
+	t.Rows = append(t.Rows, row)
+}
+
+func (t *table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if len(t.Header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+	}
+
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush() //nolint:wrapcheck
+}
+
+// printResult writes v as JSON, or as a table using toTable when the
+// table format has been selected.
+func (a *app) printResult(v interface{}, toTable func(t *table)) error {
+	if a.format == formatJSON || toTable == nil {
+		enc := json.NewEncoder(a.stdout)
+
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v) //nolint:wrapcheck
+	}
+
+	var t table
+
+	toTable(&t)
+
+	return t.write(a.stdout)
+}
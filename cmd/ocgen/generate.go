@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+// initialisms are written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "json": true,
+	"uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// goName converts a content type or property name to an exported Go
+// identifier, "metadata_mimetype" becomes MetadataMimetype.
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+
+	for _, p := range parts {
+		if initialisms[strings.ToLower(p)] {
+			b.WriteString(strings.ToUpper(p))
+
+			continue
+		}
+
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+
+		b.WriteString(string(r))
+	}
+
+	s := b.String()
+
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+
+	return s
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+
+	// Leading initialisms are lower cased as a whole.
+	i := 0
+	for i < len(r) && unicode.IsUpper(r[i]) && (i+1 == len(r) || unicode.IsUpper(r[i+1])) {
+		r[i] = unicode.ToLower(r[i])
+		i++
+	}
+
+	if i == 0 && len(r) > 0 {
+		r[0] = unicode.ToLower(r[0])
+	}
+
+	return string(r)
+}
+
+// generator writes the Go code for a schema.
+type generator struct {
+	schema      *oc.ContentTypesResponse
+	buf         bytes.Buffer
+	idents      map[string]string
+	usesTime    bool
+	usesStrconv bool
+}
+
+// generate returns formatted Go source for the content types in the
+// schema.
+func generate(schema *oc.ContentTypesResponse, pkg string) ([]byte, error) {
+	g := generator{
+		schema: schema,
+		idents: make(map[string]string),
+	}
+
+	for i := range schema.ContentTypes {
+		if err := g.contentType(&schema.ContentTypes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+
+	fmt.Fprintln(&out, "// Code generated by ocgen. DO NOT EDIT.")
+	fmt.Fprintln(&out)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	fmt.Fprintln(&out, "import (")
+
+	if g.usesStrconv {
+		fmt.Fprintln(&out, `"strconv"`)
+	}
+
+	if g.usesTime {
+		fmt.Fprintln(&out, `"time"`)
+	}
+
+	if g.usesStrconv || g.usesTime {
+		fmt.Fprintln(&out)
+	}
+
+	fmt.Fprintln(&out, `oc "github.com/navigacontentlab/oc-client-go/v2"`)
+	fmt.Fprintln(&out, ")")
+
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+// ident reserves a top level identifier, names that map to the same
+// identifier would make the generated code invalid.
+func (g *generator) ident(name string, source string) (string, error) {
+	if prev, ok := g.idents[name]; ok {
+		return "", fmt.Errorf("both %s and %s would be generated as %s", prev, source, name)
+	}
+
+	g.idents[name] = source
+
+	return name, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) contentType(ct *oc.ContentType) error {
+	typeName, err := g.ident(goName(ct.Name), "content type "+ct.Name)
+	if err != nil {
+		return err
+	}
+
+	typeConst, err := g.ident("ContentType"+typeName, "content type "+ct.Name)
+	if err != nil {
+		return err
+	}
+
+	propConsts := make([]string, len(ct.Properties))
+
+	for i, p := range ct.Properties {
+		propConsts[i], err = g.ident(typeName+goName(p.Name), ct.Name+"."+p.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	g.printf("\n// %s is the name of the %s content type.\n", typeConst, ct.Name)
+	g.printf("const %s = %q\n", typeConst, ct.Name)
+
+	g.printf("\n// %s properties.\nconst (\n", ct.Name)
+
+	for i, p := range ct.Properties {
+		g.printf("%s = %q\n", propConsts[i], p.Name)
+	}
+
+	g.printf(")\n")
+
+	g.printf("\n// %s is an object of the %s content type, see oc.DecodeProperties.\n", typeName, ct.Name)
+	g.printf("type %s struct {\n", typeName)
+
+	for _, p := range ct.Properties {
+		if p.Description != "" {
+			g.printf("// %s\n", strings.Join(strings.Fields(p.Description), " "))
+		}
+
+		g.printf("%s %s `oc:%q`\n", goName(p.Name), g.fieldType(p), p.Name)
+	}
+
+	g.printf("}\n")
+
+	return g.propertyHelpers(ct, typeName, propConsts)
+}
+
+func (g *generator) fieldType(p oc.PropertyDefinition) string {
+	var t string
+
+	switch p.Type { //nolint:exhaustive
+	case oc.PropertyTypeBoolean:
+		t = "bool"
+	case oc.PropertyTypeInteger:
+		t = "int"
+	case oc.PropertyTypeLong:
+		t = "int64"
+	case oc.PropertyTypeFloat, oc.PropertyTypeDouble:
+		t = "float64"
+	case oc.PropertyTypeDate:
+		t = "time.Time"
+		g.usesTime = true
+	case oc.PropertyTypeRelation:
+		if g.schema.Type(p.Relation) == nil {
+			return "[]oc.Properties"
+		}
+
+		if !p.MultiValued {
+			return "*" + goName(p.Relation)
+		}
+
+		return "[]" + goName(p.Relation)
+	default:
+		t = "string"
+	}
+
+	if p.MultiValued {
+		return "[]" + t
+	}
+
+	return t
+}
+
+func (g *generator) propertyHelpers(ct *oc.ContentType, typeName string, propConsts []string) error {
+	namesVar, err := g.ident(lowerFirst(typeName)+"PropertyNames", ct.Name+" property names")
+	if err != nil {
+		return err
+	}
+
+	listFunc, err := g.ident(typeName+"PropertyList", ct.Name+" property list")
+	if err != nil {
+		return err
+	}
+
+	g.printf("\nvar %s = []string{\n", namesVar)
+
+	for i, p := range ct.Properties {
+		if p.Type != oc.PropertyTypeRelation {
+			g.printf("%s,\n", propConsts[i])
+		}
+	}
+
+	g.printf("}\n")
+
+	g.printf(`
+// %[1]s returns a property list with all %[2]s properties that
+// aren't relationships, followed by the given relationships.
+func %[1]s(relationships ...*oc.PropertyReference) oc.PropertyList {
+	var pl oc.PropertyList
+
+	pl.Append(%[3]s...)
+
+	return append(pl, relationships...)
+}
+`, listFunc, ct.Name, namesVar)
+
+	for i, p := range ct.Properties {
+		source := ct.Name + "." + p.Name
+
+		switch {
+		case p.Type == oc.PropertyTypeRelation:
+			err = g.relationHelper(p, propConsts[i], source)
+		case p.IndexFieldType != oc.IndexFieldNone:
+			err = g.queryHelpers(p, propConsts[i], source)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *generator) relationHelper(p oc.PropertyDefinition, propConst string, source string) error {
+	name, err := g.ident(propConst+"Ref", source+" reference")
+	if err != nil {
+		return err
+	}
+
+	if g.schema.Type(p.Relation) == nil {
+		g.printf(`
+// %[1]s returns a reference to the %[2]s relationship with the given
+// nested properties.
+func %[1]s(nested ...string) *oc.PropertyReference {
+	return oc.NewPropertyReference(%[3]s, nested...)
+}
+`, name, p.Name, propConst)
+
+		return nil
+	}
+
+	g.printf(`
+// %[1]s returns a reference to the %[2]s relationship with the given
+// nested properties, or all %[3]s properties that aren't relationships.
+func %[1]s(nested ...string) *oc.PropertyReference {
+	if len(nested) == 0 {
+		nested = %[4]s
+	}
+
+	return oc.NewPropertyReference(%[5]s, nested...)
+}
+`, name, p.Name, p.Relation, lowerFirst(goName(p.Relation))+"PropertyNames", propConst)
+
+	return nil
+}
+
+func (g *generator) queryHelpers(p oc.PropertyDefinition, propConst string, source string) error {
+	queryFunc, err := g.ident(propConst+"Query", source+" query")
+	if err != nil {
+		return err
+	}
+
+	var rangeFunc string
+
+	switch p.Type { //nolint:exhaustive
+	case oc.PropertyTypeInteger, oc.PropertyTypeLong, oc.PropertyTypeFloat,
+		oc.PropertyTypeDouble, oc.PropertyTypeDate:
+		rangeFunc, err = g.ident(propConst+"Range", source+" range query")
+		if err != nil {
+			return err
+		}
+	}
+
+	g.printf("\n// %s returns a query clause that matches %s.\n", queryFunc, p.Name)
+
+	switch p.Type { //nolint:exhaustive
+	case oc.PropertyTypeBoolean, oc.PropertyTypeInteger, oc.PropertyTypeLong,
+		oc.PropertyTypeFloat, oc.PropertyTypeDouble:
+		g.usesStrconv = true
+	}
+
+	switch p.Type { //nolint:exhaustive
+	case oc.PropertyTypeBoolean:
+		g.printf("func %s(value bool) string {\nreturn oc.QueryTerm(%s, strconv.FormatBool(value))\n}\n",
+			queryFunc, propConst)
+	case oc.PropertyTypeInteger:
+		g.printf("func %s(value int) string {\nreturn oc.QueryTerm(%s, strconv.Itoa(value))\n}\n",
+			queryFunc, propConst)
+	case oc.PropertyTypeLong:
+		g.printf("func %s(value int64) string {\nreturn oc.QueryTerm(%s, strconv.FormatInt(value, 10))\n}\n",
+			queryFunc, propConst)
+	case oc.PropertyTypeFloat, oc.PropertyTypeDouble:
+		g.printf("func %s(value float64) string {\nreturn oc.QueryTerm(%s, strconv.FormatFloat(value, 'g', -1, 64))\n}\n",
+			queryFunc, propConst)
+	case oc.PropertyTypeDate:
+		g.printf("func %s(value time.Time) string {\nreturn oc.QueryTerm(%s, oc.FormatQueryDate(value))\n}\n",
+			queryFunc, propConst)
+	default:
+		g.printf("func %s(value string) string {\nreturn oc.QueryTerm(%s, value)\n}\n",
+			queryFunc, propConst)
+	}
+
+	if rangeFunc == "" {
+		return nil
+	}
+
+	g.printf("\n// %s returns an inclusive range query clause for %s.\n", rangeFunc, p.Name)
+
+	switch p.Type { //nolint:exhaustive
+	case oc.PropertyTypeDate:
+		g.printf("// A zero from or to time leaves that end of the range open.\n")
+		g.printf("func %s(from, to time.Time) string {\nreturn oc.QueryDateRange(%s, from, to)\n}\n",
+			rangeFunc, propConst)
+	case oc.PropertyTypeInteger:
+		g.printf("func %s(from, to int) string {\nreturn oc.QueryRange(%s, strconv.Itoa(from), strconv.Itoa(to))\n}\n",
+			rangeFunc, propConst)
+	case oc.PropertyTypeLong:
+		g.printf("func %s(from, to int64) string {\n"+
+			"return oc.QueryRange(%s, strconv.FormatInt(from, 10), strconv.FormatInt(to, 10))\n}\n",
+			rangeFunc, propConst)
+	default:
+		g.printf("func %s(from, to float64) string {\n"+
+			"return oc.QueryRange(%s, strconv.FormatFloat(from, 'g', -1, 64), strconv.FormatFloat(to, 'g', -1, 64))\n}\n",
+			rangeFunc, propConst)
+	}
+
+	return nil
+}
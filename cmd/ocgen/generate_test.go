@@ -0,0 +1,106 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"uuid":                 "UUID",
+		"metadata_mimetype":    "MetadataMimetype",
+		"ArticleMetaNewsValue": "ArticleMetaNewsValue",
+		"image-url":            "ImageURL",
+		"2col":                 "X2col",
+	}
+
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := lowerFirst("UUIDThing"); got != "uuidThing" {
+		t.Errorf("unexpected lowerFirst result %q", got)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	schema, err := readSchema("../../test/contenttypesresponse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generate(schema, "schema")
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "schema.go", src, 0)
+	if err != nil {
+		t.Fatalf("generated code doesn't parse: %v", err)
+	}
+
+	decls := make(map[string]bool)
+
+	for _, d := range file.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			decls[d.Name.Name] = true
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				switch s := s.(type) {
+				case *ast.TypeSpec:
+					decls[s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						decls[n.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"Article", "Concept", "Image", "ContentTypeArticle", "ArticleUUID",
+		"ArticlePropertyList", "ArticleConceptRelationsRef", "ArticleCreatedRange",
+		"ArticleArticleBodyQuery", "conceptPropertyNames",
+	} {
+		if !decls[name] {
+			t.Errorf("expected a declaration of %s", name)
+		}
+	}
+
+	for _, snippet := range []string{
+		"// Code generated by ocgen. DO NOT EDIT.",
+		"ConceptRelations []Concept `oc:\"ConceptRelations\"`",
+		"Created time.Time `oc:\"created\"`",
+		"Group []string `oc:\"group\"`",
+	} {
+		if !strings.Contains(string(src), snippet) {
+			t.Errorf("expected the generated code to contain %q", snippet)
+		}
+	}
+}
+
+func TestGenerate_Collision(t *testing.T) {
+	schema := oc.ContentTypesResponse{
+		ContentTypes: []oc.ContentType{{
+			Name: "Article",
+			Properties: []oc.PropertyDefinition{
+				{Name: "image_url", Type: oc.PropertyTypeString},
+				{Name: "ImageURL", Type: oc.PropertyTypeString},
+			},
+		}},
+	}
+
+	_, err := generate(&schema, "schema")
+	if err == nil || !strings.Contains(err.Error(), "ArticleImageURL") {
+		t.Errorf("expected a collision error, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Command ocgen generates Go code from the Open Content schema.
+//
+// For every content type it generates a struct with `oc` tags for
+// oc.DecodeProperties, constants for the content type and property
+// names, property list builders and query helpers for the indexed
+// properties, so that misspelled property names become compile
+// errors.
+//
+// The schema is read from a file with the output of the contenttypes
+// endpoint, or from a live OC instance.
+//
+// Usage:
+//
+//	ocgen -input test/contenttypesresponse.json -package schema -o schema/schema.go
+//	ocgen -url https://stage:8443/opencontent -package schema -o schema/schema.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	var (
+		input     string
+		output    string
+		pkg       string
+		temporary bool
+		url       string
+		username  string
+		password  string
+		token     string
+	)
+
+	flag.StringVar(&input, "input", "", "read the schema from a JSON file instead of OC")
+	flag.StringVar(&output, "o", "", "file to write the generated code to, defaults to stdout")
+	flag.StringVar(&pkg, "package", "schema", "package name of the generated code")
+	flag.BoolVar(&temporary, "temporary", false, "use the temporary configuration")
+	flag.StringVar(&url, "url", os.Getenv("OC_BASEURL"), "base URL of the OC instance, defaults to $OC_BASEURL")
+	flag.StringVar(&username, "username", os.Getenv("OC_USERNAME"), "OC username, defaults to $OC_USERNAME")
+	flag.StringVar(&password, "password", os.Getenv("OC_PASSWORD"), "OC password, defaults to $OC_PASSWORD")
+	flag.StringVar(&token, "token", os.Getenv("OC_TOKEN"), "OC bearer token, defaults to $OC_TOKEN")
+	flag.Parse()
+
+	var (
+		schema *oc.ContentTypesResponse
+		err    error
+	)
+
+	switch {
+	case input != "":
+		schema, err = readSchema(input)
+	case url != "":
+		opts := oc.Options{BaseURL: url}
+
+		switch {
+		case token != "":
+			opts.Auth = oc.BearerAuth(token)
+		case username != "":
+			opts.Auth = oc.BasicAuth(username, password)
+		}
+
+		schema, err = fetchSchema(opts, temporary)
+	default:
+		return errors.New("either an input file or an OC URL is required")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(schema, pkg)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+
+		return err //nolint:wrapcheck
+	}
+
+	if err := os.WriteFile(output, src, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to write generated code: %w", err)
+	}
+
+	return nil
+}
+
+func readSchema(path string) (*oc.ContentTypesResponse, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var schema oc.ContentTypesResponse
+
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	return &schema, nil
+}
+
+func fetchSchema(opts oc.Options, temporary bool) (*oc.ContentTypesResponse, error) {
+	client, err := oc.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	schema, err := client.ContentTypes(ctx, oc.ContentTypesRequest{
+		Temporary: temporary,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	return schema, nil
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 type ContentTypesRequest struct {
@@ -14,19 +15,346 @@ type ContentTypesRequest struct {
 }
 
 type ContentTypesResponse struct {
-	ContentTypes []struct {
-		Name       string `json:"name"`
-		Properties []struct {
-			Name           string `json:"name"`
-			Type           string `json:"type"`
-			MultiValued    bool   `json:"multiValued"`
-			Searchable     bool   `json:"searchable"`
-			ReadOnly       bool   `json:"readOnly"`
-			Description    string `json:"description"`
-			Suggest        bool   `json:"suggest"`
-			IndexFieldType string `json:"indexFieldType"`
-		} `json:"properties"`
-	} `json:"contentTypes"`
+	ContentTypes []ContentType `json:"contentTypes"`
+}
+
+// ContentType is a content type in the OC schema.
+type ContentType struct {
+	Name       string               `json:"name"`
+	Properties []PropertyDefinition `json:"properties"`
+}
+
+// PropertyDefinition describes a property of a content type.
+type PropertyDefinition struct {
+	Name string
+	Type PropertyType
+	// Relation is the related content type for relationship
+	// properties.
+	Relation       string
+	MultiValued    bool
+	Searchable     bool
+	ReadOnly       bool
+	Description    string
+	Suggest        bool
+	IndexFieldType IndexFieldType
+	// RawType and RawIndexFieldType are the type names as OC sent
+	// them, so that types that aren't known by the client are kept.
+	RawType           string
+	RawIndexFieldType string
+}
+
+type propertyDefinitionJSON struct {
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	MultiValued    bool    `json:"multiValued"`
+	Searchable     bool    `json:"searchable"`
+	ReadOnly       bool    `json:"readOnly"`
+	Description    string  `json:"description"`
+	Suggest        bool    `json:"suggest"`
+	IndexFieldType *string `json:"indexFieldType"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Types that aren't
+// primitive property types are relationships to the named content
+// type.
+func (pd *PropertyDefinition) UnmarshalJSON(data []byte) error {
+	var raw propertyDefinitionJSON
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	*pd = PropertyDefinition{
+		Name:        raw.Name,
+		Type:        ParsePropertyType(raw.Type),
+		MultiValued: raw.MultiValued,
+		Searchable:  raw.Searchable,
+		ReadOnly:    raw.ReadOnly,
+		Description: raw.Description,
+		Suggest:     raw.Suggest,
+		RawType:     raw.Type,
+	}
+
+	if pd.Type == PropertyTypeRelation {
+		pd.Relation = raw.Type
+	}
+
+	if raw.IndexFieldType != nil {
+		pd.IndexFieldType = ParseIndexFieldType(*raw.IndexFieldType)
+		pd.RawIndexFieldType = *raw.IndexFieldType
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (pd PropertyDefinition) MarshalJSON() ([]byte, error) {
+	raw := propertyDefinitionJSON{
+		Name:        pd.Name,
+		Type:        pd.TypeName(),
+		MultiValued: pd.MultiValued,
+		Searchable:  pd.Searchable,
+		ReadOnly:    pd.ReadOnly,
+		Description: pd.Description,
+		Suggest:     pd.Suggest,
+	}
+
+	if pd.IndexFieldType != IndexFieldNone {
+		name := pd.IndexFieldTypeName()
+		raw.IndexFieldType = &name
+	}
+
+	return json.Marshal(raw) //nolint:wrapcheck
+}
+
+// TypeName returns the type as OC names it, the content type name for
+// relationships. The raw name is used for types that aren't known by
+// the client.
+func (pd PropertyDefinition) TypeName() string {
+	switch {
+	case pd.Type == PropertyTypeRelation:
+		return pd.Relation
+	case pd.Type == PropertyTypeUnknown && pd.RawType != "":
+		return pd.RawType
+	default:
+		return pd.Type.String()
+	}
+}
+
+// IndexFieldTypeName returns the index field type as OC names it,
+// empty for properties that aren't indexed. The raw name is used for
+// index field types that aren't known by the client.
+func (pd PropertyDefinition) IndexFieldTypeName() string {
+	if pd.IndexFieldType == IndexFieldUnknown && pd.RawIndexFieldType != "" {
+		return pd.RawIndexFieldType
+	}
+
+	return pd.IndexFieldType.String()
+}
+
+// PropertyType is the value type of a property.
+type PropertyType int
+
+const (
+	PropertyTypeUnknown PropertyType = iota
+	PropertyTypeString
+	PropertyTypeBoolean
+	PropertyTypeInteger
+	PropertyTypeLong
+	PropertyTypeFloat
+	PropertyTypeDouble
+	PropertyTypeDate
+	PropertyTypeStream
+	// PropertyTypeRelation is a relationship to objects of another
+	// content type, with nested properties.
+	PropertyTypeRelation
+)
+
+var propertyTypeNames = map[PropertyType]string{
+	PropertyTypeString:  "STRING",
+	PropertyTypeBoolean: "BOOLEAN",
+	PropertyTypeInteger: "INTEGER",
+	PropertyTypeLong:    "LONG",
+	PropertyTypeFloat:   "FLOAT",
+	PropertyTypeDouble:  "DOUBLE",
+	PropertyTypeDate:    "DATE",
+	PropertyTypeStream:  "STREAM",
+}
+
+// ParsePropertyType parses an OC property type. Upper case names
+// that aren't known are reported as PropertyTypeUnknown, other names
+// are content types and reported as PropertyTypeRelation.
+func ParsePropertyType(s string) PropertyType {
+	for t, name := range propertyTypeNames {
+		if name == s {
+			return t
+		}
+	}
+
+	if s == "" || strings.ToUpper(s) == s {
+		return PropertyTypeUnknown
+	}
+
+	return PropertyTypeRelation
+}
+
+func (pt PropertyType) String() string {
+	if name, ok := propertyTypeNames[pt]; ok {
+		return name
+	}
+
+	if pt == PropertyTypeRelation {
+		return "relation"
+	}
+
+	return "unknown"
+}
+
+// IndexFieldType is how a property is indexed for search.
+type IndexFieldType int
+
+const (
+	// IndexFieldNone is used for properties that aren't indexed.
+	IndexFieldNone IndexFieldType = iota
+	IndexFieldString
+	IndexFieldStringLowercase
+	IndexFieldText
+	IndexFieldBoolean
+	IndexFieldInteger
+	IndexFieldLong
+	IndexFieldFloat
+	IndexFieldDouble
+	IndexFieldDate
+	IndexFieldUnknown
+)
+
+var indexFieldTypeNames = map[IndexFieldType]string{
+	IndexFieldString:          "STRING",
+	IndexFieldStringLowercase: "STRING_LOWERCASE",
+	IndexFieldText:            "TEXT",
+	IndexFieldBoolean:         "BOOLEAN",
+	IndexFieldInteger:         "INTEGER",
+	IndexFieldLong:            "LONG",
+	IndexFieldFloat:           "FLOAT",
+	IndexFieldDouble:          "DOUBLE",
+	IndexFieldDate:            "DATE",
+}
+
+// ParseIndexFieldType parses an OC index field type, an empty string
+// means that the property isn't indexed.
+func ParseIndexFieldType(s string) IndexFieldType {
+	if s == "" {
+		return IndexFieldNone
+	}
+
+	for t, name := range indexFieldTypeNames {
+		if name == s {
+			return t
+		}
+	}
+
+	return IndexFieldUnknown
+}
+
+func (it IndexFieldType) String() string {
+	if name, ok := indexFieldTypeNames[it]; ok {
+		return name
+	}
+
+	if it == IndexFieldNone {
+		return ""
+	}
+
+	return "unknown"
+}
+
+// UnmarshalJSON implements json.Unmarshaler, null is IndexFieldNone.
+func (it *IndexFieldType) UnmarshalJSON(data []byte) error {
+	var s *string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	*it = IndexFieldNone
+
+	if s != nil {
+		*it = ParseIndexFieldType(*s)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (it IndexFieldType) MarshalJSON() ([]byte, error) {
+	if it == IndexFieldNone {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(it.String()) //nolint:wrapcheck
+}
+
+// Type returns the named content type, or nil if it doesn't exist.
+func (cr *ContentTypesResponse) Type(name string) *ContentType {
+	for i := range cr.ContentTypes {
+		if cr.ContentTypes[i].Name == name {
+			return &cr.ContentTypes[i]
+		}
+	}
+
+	return nil
+}
+
+// Property returns the definition of a property of a content type,
+// or nil if it doesn't exist. Properties of related objects are
+// looked up with dotted paths, "ConceptRelations.ConceptName" is the
+// ConceptName property of the content type that ConceptRelations
+// relates to.
+func (cr *ContentTypesResponse) Property(contentType string, name string) *PropertyDefinition {
+	ct := cr.Type(contentType)
+	if ct == nil {
+		return nil
+	}
+
+	head, rest, nested := strings.Cut(name, ".")
+
+	prop := ct.Property(head)
+	if prop == nil || !nested {
+		return prop
+	}
+
+	if prop.Type != PropertyTypeRelation {
+		return nil
+	}
+
+	return cr.Property(prop.Relation, rest)
+}
+
+// SearchableFields returns the searchable properties of a content
+// type.
+func (cr *ContentTypesResponse) SearchableFields(contentType string) []PropertyDefinition {
+	return cr.filterProperties(contentType, func(p PropertyDefinition) bool {
+		return p.Searchable
+	})
+}
+
+// SuggestFields returns the properties of a content type that can be
+// used for suggestions.
+func (cr *ContentTypesResponse) SuggestFields(contentType string) []PropertyDefinition {
+	return cr.filterProperties(contentType, func(p PropertyDefinition) bool {
+		return p.Suggest
+	})
+}
+
+func (cr *ContentTypesResponse) filterProperties(
+	contentType string, include func(p PropertyDefinition) bool,
+) []PropertyDefinition {
+	ct := cr.Type(contentType)
+	if ct == nil {
+		return nil
+	}
+
+	var res []PropertyDefinition
+
+	for _, p := range ct.Properties {
+		if include(p) {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+// Property returns the named property definition, or nil if it
+// doesn't exist.
+func (ct *ContentType) Property(name string) *PropertyDefinition {
+	for i := range ct.Properties {
+		if ct.Properties[i].Name == name {
+			return &ct.Properties[i]
+		}
+	}
+
+	return nil
 }
 
 func (cr *ContentTypesRequest) QueryValues() (url.Values, error) {
@@ -1,6 +1,8 @@
 package oc_test
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 
 	oc "github.com/navigacontentlab/oc-client-go/v2"
@@ -36,3 +38,160 @@ func TestContentTypesResponse_Unmarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestContentTypesResponse_Lookup(t *testing.T) {
+	var resp oc.ContentTypesResponse
+
+	loadTestData(t, "contenttypesresponse.json", &resp)
+
+	if resp.Type("Unknown") != nil {
+		t.Error("expected no result for an unknown content type")
+	}
+
+	uuidProp := resp.Property("Article", "uuid")
+	if uuidProp == nil {
+		t.Fatal("expected to find Article.uuid")
+	}
+
+	if uuidProp.Type != oc.PropertyTypeString || uuidProp.IndexFieldType != oc.IndexFieldString {
+		t.Errorf("unexpected types for Article.uuid: %v, %v", uuidProp.Type, uuidProp.IndexFieldType)
+	}
+
+	rel := resp.Property("Article", "ConceptRelations")
+	if rel == nil {
+		t.Fatal("expected to find Article.ConceptRelations")
+	}
+
+	if rel.Type != oc.PropertyTypeRelation || rel.Relation != "Concept" {
+		t.Errorf("expected a relation to Concept, got %v to %q", rel.Type, rel.Relation)
+	}
+
+	if rel.IndexFieldType != oc.IndexFieldNone {
+		t.Errorf("expected a null index field type, got %v", rel.IndexFieldType)
+	}
+
+	nested := resp.Property("Article", "ConceptRelations.ConceptAssociatedWithType")
+	if nested == nil || nested.Name != "ConceptAssociatedWithType" {
+		t.Errorf("expected to find nested property, got %v", nested)
+	}
+
+	if resp.Property("Article", "uuid.version") != nil {
+		t.Error("expected no nested property of a non-relation")
+	}
+
+	if resp.Property("Article", "ConceptRelations.Unknown") != nil {
+		t.Error("expected no result for an unknown nested property")
+	}
+
+	assertNames(t, "searchable", resp.SearchableFields("Article"),
+		"ArticleBody", "ArticleMetaNewsValue")
+	assertNames(t, "suggest", resp.SuggestFields("Article"),
+		"contenttype", "uuid", "ArticleMetaNewsValue", "ObjectCreator")
+}
+
+func TestContentTypesResponse_RoundTrip(t *testing.T) {
+	var resp oc.ContentTypesResponse
+
+	loadTestData(t, "contenttypesresponse.json", &resp)
+
+	data, err := json.Marshal(&resp)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var again oc.ContentTypesResponse
+
+	if err := json.Unmarshal(data, &again); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(resp, again) {
+		t.Error("content types changed in a marshal round trip")
+	}
+}
+
+func TestParsePropertyType(t *testing.T) {
+	tests := map[string]oc.PropertyType{
+		"STRING":  oc.PropertyTypeString,
+		"DATE":    oc.PropertyTypeDate,
+		"STREAM":  oc.PropertyTypeStream,
+		"Concept": oc.PropertyTypeRelation,
+		"FANCY":   oc.PropertyTypeUnknown,
+		"":        oc.PropertyTypeUnknown,
+	}
+
+	for in, want := range tests {
+		if got := oc.ParsePropertyType(in); got != want {
+			t.Errorf("ParsePropertyType(%q) = %v, want %v", in, got, want)
+		}
+	}
+
+	if got := oc.ParseIndexFieldType("STRING_LOWERCASE"); got != oc.IndexFieldStringLowercase {
+		t.Errorf("unexpected index field type %v", got)
+	}
+
+	if got := oc.ParseIndexFieldType("FANCY"); got != oc.IndexFieldUnknown {
+		t.Errorf("unexpected index field type %v", got)
+	}
+}
+
+func TestPropertyDefinition_UnknownTypes(t *testing.T) {
+	var active, temp oc.PropertyDefinition
+
+	if err := json.Unmarshal(
+		[]byte(`{"name":"Shape","type":"GEOMETRY","indexFieldType":"GEO_SHAPE"}`), &active,
+	); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if active.Type != oc.PropertyTypeUnknown || active.IndexFieldType != oc.IndexFieldUnknown {
+		t.Errorf("expected unknown types, got %v and %v", active.Type, active.IndexFieldType)
+	}
+
+	if active.TypeName() != "GEOMETRY" || active.IndexFieldTypeName() != "GEO_SHAPE" {
+		t.Errorf("expected the raw type names, got %q and %q",
+			active.TypeName(), active.IndexFieldTypeName())
+	}
+
+	data, err := json.Marshal(active)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(active, temp) {
+		t.Errorf("property changed in a marshal round trip: %s", data)
+	}
+
+	temp.RawType = "GEOPOINT"
+
+	diffs := oc.SchemaDiff(
+		&oc.ContentTypesResponse{ContentTypes: []oc.ContentType{
+			{Name: "Place", Properties: []oc.PropertyDefinition{active}},
+		}},
+		&oc.ContentTypesResponse{ContentTypes: []oc.ContentType{
+			{Name: "Place", Properties: []oc.PropertyDefinition{temp}},
+		}},
+	)
+
+	if len(diffs) != 1 || diffs[0].Old != "GEOMETRY" || diffs[0].New != "GEOPOINT" {
+		t.Errorf("expected the raw type names in the difference, got %v", diffs)
+	}
+}
+
+func assertNames(t *testing.T, what string, props []oc.PropertyDefinition, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(props))
+
+	for i, p := range props {
+		got[i] = p.Name
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected %s fields %v, want %v", what, got, want)
+	}
+}
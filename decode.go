@@ -0,0 +1,150 @@
+package oc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	propertiesType = reflect.TypeOf(Properties(nil))
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// DecodeProperties decodes search hit or property values into the
+// struct that v points to. Fields are matched with properties by
+// their `oc` struct tag, fields without a tag are left as they are.
+//
+// Fields can be strings, booleans, integers, floats and time.Time
+// values, or slices of them for multi-valued properties. Relationships
+// are decoded into structs, struct pointers or Properties, or slices
+// of them.
+func DecodeProperties(p Properties, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("properties can only be decoded into a struct pointer")
+	}
+
+	return decodeStruct(p, rv.Elem())
+}
+
+func decodeStruct(p Properties, sv reflect.Value) error {
+	st := sv.Type()
+
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+
+		name, ok := f.Tag.Lookup("oc")
+		if !ok || name == "-" || !f.IsExported() {
+			continue
+		}
+
+		values, ok := p[name]
+		if !ok {
+			continue
+		}
+
+		if err := decodeField(sv.Field(i), values); err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func decodeField(fv reflect.Value, values []interface{}) error {
+	if fv.Kind() != reflect.Slice {
+		if len(values) == 0 {
+			return nil
+		}
+
+		return decodeValue(fv, values[0])
+	}
+
+	s := reflect.MakeSlice(fv.Type(), len(values), len(values))
+
+	for i := range values {
+		if err := decodeValue(s.Index(i), values[i]); err != nil {
+			return err
+		}
+	}
+
+	fv.Set(s)
+
+	return nil
+}
+
+func decodeValue(v reflect.Value, value interface{}) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+
+		if err := decodeValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+
+		v.Set(ptr)
+
+		return nil
+	}
+
+	if v.Type() == propertiesType || (v.Kind() == reflect.Struct && v.Type() != timeType) {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected a relationship, got %T", value)
+		}
+
+		rel := relationshipProperties(m)
+
+		if v.Type() == propertiesType {
+			v.Set(reflect.ValueOf(rel))
+
+			return nil
+		}
+
+		return decodeStruct(rel, v)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetFloat(f)
+	case reflect.Struct:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.Set(reflect.ValueOf(t))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package oc_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+type testConcept struct {
+	UUID string `oc:"uuid"`
+	Name string `oc:"ConceptName"`
+}
+
+type testArticle struct {
+	UUID     string          `oc:"uuid"`
+	Headline string          `oc:"Headline"`
+	Version  int             `oc:"version"`
+	Deleted  bool            `oc:"deleted"`
+	Score    float64         `oc:"Score"`
+	Updated  time.Time       `oc:"updated"`
+	Groups   []string        `oc:"group"`
+	Concepts []testConcept   `oc:"ConceptRelations"`
+	Main     *testConcept    `oc:"MainConcept"`
+	Raw      []oc.Properties `oc:"Other"`
+	Untagged string
+	Ignored  string `oc:"-"`
+}
+
+func TestDecodeProperties(t *testing.T) {
+	data := []byte(`{
+  "uuid": ["a1"],
+  "Headline": ["Scooters"],
+  "version": ["3"],
+  "deleted": ["false"],
+  "Score": ["0.5"],
+  "updated": ["2021-03-04T05:06:07Z"],
+  "group": ["news", "sports"],
+  "ConceptRelations": [
+    {"uuid": ["c1"], "ConceptName": ["Stockholm"]},
+    {"uuid": ["c2"], "ConceptName": ["Oslo"]}
+  ],
+  "MainConcept": [{"uuid": ["c1"]}],
+  "Other": [{"uuid": ["o1"]}],
+  "Untagged": ["x"],
+  "-": ["x"]
+}`)
+
+	var props oc.Properties
+
+	if err := json.Unmarshal(data, &props); err != nil {
+		t.Fatalf("failed to unmarshal properties: %v", err)
+	}
+
+	var got testArticle
+
+	if err := oc.DecodeProperties(props, &got); err != nil {
+		t.Fatalf("failed to decode properties: %v", err)
+	}
+
+	want := testArticle{
+		UUID:     "a1",
+		Headline: "Scooters",
+		Version:  3,
+		Score:    0.5,
+		Updated:  time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		Groups:   []string{"news", "sports"},
+		Concepts: []testConcept{
+			{UUID: "c1", Name: "Stockholm"},
+			{UUID: "c2", Name: "Oslo"},
+		},
+		Main: &testConcept{UUID: "c1"},
+		Raw:  []oc.Properties{{"uuid": {"o1"}}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DecodeProperties() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeProperties_Errors(t *testing.T) {
+	var a testArticle
+
+	err := oc.DecodeProperties(oc.Properties{"version": {"three"}}, &a)
+	if err == nil {
+		t.Error("expected an error for an invalid integer")
+	}
+
+	err = oc.DecodeProperties(oc.Properties{"ConceptRelations": {"c1"}}, &a)
+	if err == nil {
+		t.Error("expected an error for a relationship that isn't an object")
+	}
+
+	if err := oc.DecodeProperties(oc.Properties{}, a); err == nil {
+		t.Error("expected an error when not decoding into a pointer")
+	}
+}
@@ -0,0 +1,492 @@
+package oc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType is the kind of a difference between two versions of an
+// object, or between two schemas.
+type ChangeType int
+
+const (
+	// ChangeAdded is something that only exists in the newer side.
+	ChangeAdded ChangeType = iota
+	// ChangeRemoved is something that only exists in the older side.
+	ChangeRemoved
+	// ChangeChanged is something that exists on both sides but with
+	// different values.
+	ChangeChanged
+)
+
+func (ct ChangeType) String() string {
+	switch ct {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// VersionDiff describes the differences between two versions of an
+// object.
+type VersionDiff struct {
+	UUID       string
+	From       int64
+	To         int64
+	Properties []PropertyChange
+	Files      []FileChange
+}
+
+// PropertyChange is a change to a property value. Nested properties
+// are addressed as "Relation[key].Property", where key is the uuid
+// of the related object, or the index of the value if the nested
+// property has no uuid.
+type PropertyChange struct {
+	Type ChangeType
+	Path string
+	Old  []string
+	New  []string
+}
+
+// FileChange is a change to a metadata file. Unified is a unified
+// diff of the file contents for changed XML files.
+type FileChange struct {
+	Type     ChangeType
+	Name     string
+	Mimetype string
+	Unified  string
+}
+
+// DiffVersions compares the properties and metadata files of two
+// versions of an object. A primary file with a XML mimetype is
+// compared as well, as that is where articles keep their metadata.
+func (c *Client) DiffVersions(ctx context.Context, uuid string, a, b int64) (*VersionDiff, error) {
+	diff := VersionDiff{
+		UUID: uuid,
+		From: a,
+		To:   b,
+	}
+
+	propsA, err := c.PropertiesVersion(ctx, uuid, a, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get properties for version %d: %w", a, err)
+	}
+
+	propsB, err := c.PropertiesVersion(ctx, uuid, b, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get properties for version %d: %w", b, err)
+	}
+
+	diff.Properties = DiffProperties(propsA, propsB)
+
+	filesA, err := c.diffableFiles(ctx, uuid, a)
+	if err != nil {
+		return nil, err
+	}
+
+	filesB, err := c.diffableFiles(ctx, uuid, b)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fa := range filesA {
+		idx := slices.IndexFunc(filesB, func(f ObjectFile) bool {
+			return f.Name == fa.Name
+		})
+		if idx == -1 {
+			diff.Files = append(diff.Files, FileChange{
+				Type: ChangeRemoved, Name: fa.Name, Mimetype: fa.Mimetype,
+			})
+
+			continue
+		}
+
+		change, err := c.diffFile(ctx, uuid, a, b, fa, filesB[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		if change != nil {
+			diff.Files = append(diff.Files, *change)
+		}
+	}
+
+	for _, fb := range filesB {
+		if !slices.ContainsFunc(filesA, func(f ObjectFile) bool {
+			return f.Name == fb.Name
+		}) {
+			diff.Files = append(diff.Files, FileChange{
+				Type: ChangeAdded, Name: fb.Name, Mimetype: fb.Mimetype,
+			})
+		}
+	}
+
+	return &diff, nil
+}
+
+func (c *Client) diffableFiles(ctx context.Context, uuid string, version int64) ([]ObjectFile, error) {
+	list, err := c.ListFiles(ctx, uuid, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files for version %d: %w", version, err)
+	}
+
+	var files []ObjectFile
+
+	if isXMLMimetype(list.Primary.Mimetype) {
+		files = append(files, list.Primary)
+	}
+
+	for _, f := range list.Metadata {
+		if f.Name != list.Primary.Name {
+			files = append(files, f)
+		}
+	}
+
+	return files, nil
+}
+
+func (c *Client) diffFile(
+	ctx context.Context, uuid string, a, b int64, fa, fb ObjectFile,
+) (*FileChange, error) {
+	dataA, err := c.readFile(ctx, uuid, fa.Name, a)
+	if err != nil {
+		return nil, err
+	}
+
+	dataB, err := c.readFile(ctx, uuid, fb.Name, b)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(dataA, dataB) {
+		return nil, nil //nolint:nilnil
+	}
+
+	change := FileChange{
+		Type:     ChangeChanged,
+		Name:     fb.Name,
+		Mimetype: fb.Mimetype,
+	}
+
+	if isXMLMimetype(fa.Mimetype) && isXMLMimetype(fb.Mimetype) {
+		change.Unified = UnifiedDiff(
+			fmt.Sprintf("%s@%d", fa.Name, a), fmt.Sprintf("%s@%d", fb.Name, b),
+			string(dataA), string(dataB),
+		)
+	}
+
+	return &change, nil
+}
+
+func (c *Client) readFile(ctx context.Context, uuid string, name string, version int64) ([]byte, error) {
+	res, err := c.GetFile(ctx, uuid, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %q for version %d: %w", name, version, err)
+	}
+
+	defer safeClose(c.logger, "file body", res.Body)
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %q for version %d: %w", name, version, err)
+	}
+
+	return data, nil
+}
+
+func isXMLMimetype(mimetype string) bool {
+	return strings.Contains(mimetype, "xml")
+}
+
+// DiffProperties compares two property results and returns the
+// changes sorted by path.
+func DiffProperties(a, b *PropertyResult) []PropertyChange {
+	flatA := make(map[string][]string)
+	flatB := make(map[string][]string)
+
+	flattenProperties(flatA, "", a)
+	flattenProperties(flatB, "", b)
+
+	var changes []PropertyChange
+
+	for path, oldValues := range flatA {
+		newValues, ok := flatB[path]
+
+		switch {
+		case !ok:
+			changes = append(changes, PropertyChange{
+				Type: ChangeRemoved, Path: path, Old: oldValues,
+			})
+		case !slices.Equal(oldValues, newValues):
+			changes = append(changes, PropertyChange{
+				Type: ChangeChanged, Path: path, Old: oldValues, New: newValues,
+			})
+		}
+	}
+
+	for path, newValues := range flatB {
+		if _, ok := flatA[path]; !ok {
+			changes = append(changes, PropertyChange{
+				Type: ChangeAdded, Path: path, New: newValues,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+func flattenProperties(flat map[string][]string, prefix string, res *PropertyResult) {
+	if res == nil {
+		return
+	}
+
+	for _, prop := range res.Properties {
+		path := prefix + prop.Name
+
+		var values []string
+
+		for i, v := range prop.Values {
+			if v.NestedProperty == nil {
+				values = append(values, v.Value)
+
+				continue
+			}
+
+			key := strconv.Itoa(i)
+
+			for _, np := range v.NestedProperty.Properties {
+				if strings.EqualFold(np.Name, "uuid") && len(np.Values) == 1 {
+					key = np.Values[0].Value
+				}
+			}
+
+			flattenProperties(flat, path+"["+key+"].", v.NestedProperty)
+		}
+
+		if values != nil || len(prop.Values) == 0 {
+			flat[path] = values
+		}
+	}
+}
+
+const unifiedContext = 3
+
+// UnifiedDiff returns a line based unified diff between a and b, or
+// an empty string if they are equal.
+func UnifiedDiff(nameA, nameB string, a, b string) string {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+
+	ops := diffLines(linesA, linesB)
+
+	if !slices.ContainsFunc(ops, func(op diffOp) bool {
+		return op.Kind != ' '
+	}) {
+		return ""
+	}
+
+	var buf strings.Builder
+
+	buf.WriteString("--- " + nameA + "\n")
+	buf.WriteString("+++ " + nameB + "\n")
+
+	for _, h := range diffHunks(ops) {
+		hunk := ops[h[0]:h[1]]
+
+		var countA, countB int
+
+		for _, op := range hunk {
+			if op.Kind != '+' {
+				countA++
+			}
+
+			if op.Kind != '-' {
+				countB++
+			}
+		}
+
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n",
+			hunkRange(hunk[0].LineA, countA),
+			hunkRange(hunk[0].LineB, countB))
+
+		for _, op := range hunk {
+			buf.WriteByte(op.Kind)
+			buf.WriteString(op.Text)
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(count)
+}
+
+// diffHunks groups the changes in ops into hunks with surrounding
+// context, returned as [start, end) index pairs.
+func diffHunks(ops []diffOp) [][2]int {
+	var hunks [][2]int
+
+	for i := range ops {
+		if ops[i].Kind == ' ' {
+			continue
+		}
+
+		start := max(i-unifiedContext, 0)
+		end := min(i+unifiedContext+1, len(ops))
+
+		if len(hunks) > 0 && hunks[len(hunks)-1][1] >= start {
+			hunks[len(hunks)-1][1] = end
+
+			continue
+		}
+
+		hunks = append(hunks, [2]int{start, end})
+	}
+
+	return hunks
+}
+
+type diffOp struct {
+	// Kind is ' ' for unchanged lines, '-' for removed lines, and
+	// '+' for added lines.
+	Kind byte
+	Text string
+	// LineA and LineB are the zero-based line positions of the
+	// operation in a and b.
+	LineA int
+	LineB int
+}
+
+// diffLines calculates the shortest edit script between a and b
+// using the Myers diff algorithm. Only the diagonals reached in each
+// round are kept for the backtracking, so the memory use grows with
+// the square of the number of edits rather than with the number of
+// edits times the length of the input.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+
+	// trace[d] holds the furthest x reached on each diagonal k of
+	// round d, for k from -d to d in steps of two, see bandIndex.
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		band := make([]int, d+1)
+		found := false
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			switch {
+			case d == 0:
+			case k == -d || (k != d && trace[d-1][bandIndex(d-1, k-1)] < trace[d-1][bandIndex(d-1, k+1)]):
+				x = trace[d-1][bandIndex(d-1, k+1)]
+			default:
+				x = trace[d-1][bandIndex(d-1, k-1)] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			band[bandIndex(d, k)] = x
+
+			if x >= n && y >= m {
+				found = true
+
+				break
+			}
+		}
+
+		trace = append(trace, band)
+
+		if found {
+			break
+		}
+	}
+
+	var ops []diffOp
+
+	x, y := n, m
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+
+		var prevK int
+
+		if k == -d || (k != d && prev[bandIndex(d-1, k-1)] < prev[bandIndex(d-1, k+1)]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := prev[bandIndex(d-1, prevK)]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{Kind: ' ', Text: a[x], LineA: x, LineB: y})
+		}
+
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{Kind: '+', Text: b[y], LineA: x, LineB: y})
+		} else {
+			x--
+			ops = append(ops, diffOp{Kind: '-', Text: a[x], LineA: x, LineB: y})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{Kind: ' ', Text: a[x], LineA: x, LineB: y})
+	}
+
+	slices.Reverse(ops)
+
+	return ops
+}
+
+// bandIndex returns the position of diagonal k in the trace of round
+// d of diffLines.
+func bandIndex(d, k int) int {
+	return (k + d) / 2
+}
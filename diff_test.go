@@ -0,0 +1,180 @@
+package oc_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func stringProp(name string, values ...string) oc.Property {
+	prop := oc.Property{Name: name, MultiValued: len(values) > 1}
+
+	for _, v := range values {
+		prop.Values = append(prop.Values, oc.PropertyValue{Value: v})
+	}
+
+	return prop
+}
+
+func nestedProp(name string, nested ...[]oc.Property) oc.Property {
+	prop := oc.Property{Name: name, MultiValued: true}
+
+	for _, props := range nested {
+		prop.Values = append(prop.Values, oc.PropertyValue{
+			NestedProperty: &oc.PropertyResult{Properties: props},
+		})
+	}
+
+	return prop
+}
+
+func TestClient_DiffVersions(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	v1 := testImageVersion("image", "<newsItem>\n<title>Old</title>\n<keep/>\n</newsItem>\n")
+	v1.Properties = []oc.Property{
+		stringProp("Headline", "Old headline"),
+		stringProp("Byline", "Jane Doe"),
+		nestedProp("ConceptRelations",
+			[]oc.Property{stringProp("uuid", "c1"), stringProp("Name", "Sweden")},
+			[]oc.Property{stringProp("uuid", "c2"), stringProp("Name", "Norway")},
+		),
+	}
+
+	v2 := testImageVersion("image", "<newsItem>\n<title>New</title>\n<keep/>\n</newsItem>\n")
+	v2.Properties = []oc.Property{
+		stringProp("Headline", "New headline"),
+		stringProp("Keywords", "a", "b"),
+		nestedProp("ConceptRelations",
+			[]oc.Property{stringProp("uuid", "c2"), stringProp("Name", "Norge")},
+		),
+	}
+
+	fake.AddVersion(testImageUUID, v1)
+	fake.AddVersion(testImageUUID, v2)
+
+	diff, err := client.DiffVersions(context.Background(), testImageUUID, 1, 2)
+	if err != nil {
+		t.Fatalf("failed to diff versions: %v", err)
+	}
+
+	wantProps := []oc.PropertyChange{
+		{Type: oc.ChangeRemoved, Path: "Byline", Old: []string{"Jane Doe"}},
+		{Type: oc.ChangeRemoved, Path: "ConceptRelations[c1].Name", Old: []string{"Sweden"}},
+		{Type: oc.ChangeRemoved, Path: "ConceptRelations[c1].uuid", Old: []string{"c1"}},
+		{
+			Type: oc.ChangeChanged, Path: "ConceptRelations[c2].Name",
+			Old: []string{"Norway"}, New: []string{"Norge"},
+		},
+		{
+			Type: oc.ChangeChanged, Path: "Headline",
+			Old: []string{"Old headline"}, New: []string{"New headline"},
+		},
+		{Type: oc.ChangeAdded, Path: "Keywords", New: []string{"a", "b"}},
+	}
+
+	if d := cmp.Diff(wantProps, diff.Properties); d != "" {
+		t.Errorf("property changes mismatch (-want +got):\n%s", d)
+	}
+
+	wantFiles := []oc.FileChange{{
+		Type:     oc.ChangeChanged,
+		Name:     "sample-image.metadata.xml",
+		Mimetype: "application/vnd.iptc.g2.newsitem+xml.picture",
+		Unified: `--- sample-image.metadata.xml@1
++++ sample-image.metadata.xml@2
+@@ -1,4 +1,4 @@
+ <newsItem>
+-<title>Old</title>
++<title>New</title>
+ <keep/>
+ </newsItem>
+`,
+	}}
+
+	if d := cmp.Diff(wantFiles, diff.Files); d != "" {
+		t.Errorf("file changes mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n11\n12\n13\n"
+
+	want := `--- a
++++ b
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -7,6 +8,6 @@
+ 7
+ 8
+ 9
+-10
+ 11
+ 12
++13
+`
+
+	if d := cmp.Diff(want, oc.UnifiedDiff("a", "b", a, b)); d != "" {
+		t.Errorf("UnifiedDiff() mismatch (-want +got):\n%s", d)
+	}
+
+	if got := oc.UnifiedDiff("a", "b", a, a); got != "" {
+		t.Errorf("expected no diff for equal input, got:\n%s", got)
+	}
+}
+
+func TestUnifiedDiff__Large(t *testing.T) {
+	var a, b strings.Builder
+
+	for i := 0; i < 50000; i++ {
+		fmt.Fprintf(&a, "line %d\n", i)
+
+		switch {
+		case i == 25000:
+			b.WriteString("changed\n")
+		case i%10000 == 5:
+		default:
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+
+	got := oc.UnifiedDiff("a", "b", a.String(), b.String())
+
+	var removed, added int
+
+	for _, line := range strings.Split(got, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		case strings.HasPrefix(line, "-"):
+			removed++
+		case strings.HasPrefix(line, "+"):
+			added++
+		}
+	}
+
+	if removed != 6 || added != 1 {
+		t.Errorf("expected 6 removed and 1 added line, got %d and %d:\n%s", removed, added, got)
+	}
+}
+
+func TestUnifiedDiff__Replaced(t *testing.T) {
+	want := `--- a
++++ b
+@@ -1,2 +1 @@
+-x
+-y
++z
+`
+
+	if d := cmp.Diff(want, oc.UnifiedDiff("a", "b", "x\ny\n", "z\n")); d != "" {
+		t.Errorf("UnifiedDiff() mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,304 @@
+package oc
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDownloadResumes     = 5
+	defaultDownloadResumeDelay = time.Second
+)
+
+// ErrVerificationFailed is returned when a downloaded file doesn't
+// match the size or checksum reported by OC.
+var ErrVerificationFailed = errors.New("downloaded file failed verification")
+
+// DownloadOptions controls the behaviour of DownloadFile.
+type DownloadOptions struct {
+	// Progress is called after every write to the destination with
+	// the number of bytes written so far and the total size of the
+	// file, or -1 if the size isn't known.
+	Progress func(written, total int64)
+	// MaxResumes is the number of times an interrupted download
+	// will be resumed before giving up. Defaults to 5, a negative
+	// value disables resumption.
+	MaxResumes int
+	// ResumeDelay is the time to wait before resuming an
+	// interrupted download. Defaults to one second.
+	ResumeDelay time.Duration
+}
+
+// DownloadResponse describes a completed download.
+type DownloadResponse struct {
+	Size        int64
+	ETag        string
+	ContentType string
+	Version     int64
+	// Resumes is the number of times the download was resumed
+	// after an interruption.
+	Resumes int
+}
+
+// DownloadFile downloads a file of an object to dst. The file is
+// written to a temporary file in the same directory which is renamed
+// to dst once the download is complete and has been verified against
+// the size and MD5 ETag reported by OC. Interrupted transfers are
+// resumed using range requests. If version is 0 the current version
+// is downloaded, and the download is pinned to that version when
+// resuming.
+func (c *Client) DownloadFile(
+	ctx context.Context, uuid string, filename string, version int64, dst string,
+	opts *DownloadOptions,
+) (_ *DownloadResponse, outErr error) {
+	var options DownloadOptions
+
+	if opts != nil {
+		options = *opts
+	}
+
+	switch {
+	case options.MaxResumes == 0:
+		options.MaxResumes = defaultDownloadResumes
+	case options.MaxResumes < 0:
+		options.MaxResumes = 0
+	}
+
+	if options.ResumeDelay == 0 {
+		options.ResumeDelay = defaultDownloadResumeDelay
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.part")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	defer func() {
+		if outErr == nil {
+			return
+		}
+
+		_ = tmp.Close()
+
+		if err := os.Remove(tmp.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			c.logger.Logf("failed to remove temporary file: %v", err)
+		}
+	}()
+
+	d := download{
+		client:   c,
+		uuid:     uuid,
+		filename: filename,
+		file:     tmp,
+		hash:     md5.New(), //nolint:gosec
+		progress: options.Progress,
+		res: DownloadResponse{
+			Version: version,
+			Size:    -1,
+		},
+	}
+
+	for {
+		transient, err := d.fetch(ctx)
+		if err == nil {
+			break
+		}
+
+		if !transient || ctx.Err() != nil || d.res.Resumes >= options.MaxResumes {
+			return nil, fmt.Errorf("failed to download %q: %w", filename, err)
+		}
+
+		c.logger.Logf("resuming interrupted download of %q at %d bytes: %v",
+			filename, d.written, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to download %q: %w", filename, ctx.Err())
+		case <-time.After(options.ResumeDelay):
+		}
+
+		d.res.Resumes++
+	}
+
+	if err := d.verify(); err != nil {
+		return nil, err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return nil, fmt.Errorf("failed to move download into place: %w", err)
+	}
+
+	d.res.Size = d.written
+
+	return &d.res, nil
+}
+
+type download struct {
+	client   *Client
+	uuid     string
+	filename string
+	file     *os.File
+	hash     hash.Hash
+	written  int64
+	progress func(written, total int64)
+	res      DownloadResponse
+}
+
+// fetch requests the remaining part of the file and writes it to
+// disk. Returns true together with the error if the download can be
+// resumed, that is for transport errors and server errors.
+func (d *download) fetch(ctx context.Context) (bool, error) {
+	var opts []fetchOption
+
+	if d.written > 0 {
+		opts = append(opts, fetchWithHeader("Range", fmt.Sprintf("bytes=%d-", d.written)))
+
+		if d.res.ETag != "" {
+			opts = append(opts, fetchWithHeader("If-Range", d.res.ETag))
+		}
+	}
+
+	res, err := d.client.fetchFile(ctx, d.uuid, d.filename, d.res.Version, opts...)
+	if err != nil {
+		var re *ResponseError
+
+		if errors.As(err, &re) {
+			return re.Response.StatusCode >= http.StatusInternalServerError, err
+		}
+
+		return true, err
+	}
+
+	defer safeClose(d.client.logger, "download body", res.Body)
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		// We either asked for the whole file, or the file has
+		// changed and OC sent it all over again.
+		if err := d.reset(); err != nil {
+			return false, err
+		}
+
+		d.res.ETag = res.Header.Get("ETag")
+		d.res.ContentType = res.Header.Get("Content-Type")
+		d.res.Size = res.ContentLength
+
+		v, err := objectVersionFromHeader(res.Header, versionOptional)
+		if err != nil {
+			d.client.logger.Logf("failed to get version for download: %v", err)
+		}
+
+		if d.res.Version == 0 {
+			d.res.Version = v
+		}
+	case http.StatusPartialContent:
+		cr, err := ParseContentRange(res.Header.Get("Content-Range"))
+		if err != nil {
+			return false, err
+		}
+
+		if cr.Start != d.written {
+			return false, fmt.Errorf(
+				"server resumed at byte %d, expected %d", cr.Start, d.written)
+		}
+
+		if cr.Size >= 0 {
+			d.res.Size = cr.Size
+		}
+	default:
+		return res.StatusCode >= http.StatusInternalServerError, newResponseError(res)
+	}
+
+	return d.copy(res.Body)
+}
+
+func (d *download) reset() error {
+	if d.written == 0 {
+		return nil
+	}
+
+	if err := d.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate temporary file: %w", err)
+	}
+
+	if _, err := d.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind temporary file: %w", err)
+	}
+
+	d.hash.Reset()
+	d.written = 0
+
+	return nil
+}
+
+func (d *download) copy(body io.Reader) (bool, error) {
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if _, err := d.file.Write(buf[:n]); err != nil {
+				return false, fmt.Errorf("failed to write to temporary file: %w", err)
+			}
+
+			_, _ = d.hash.Write(buf[:n])
+			d.written += int64(n)
+
+			if d.progress != nil {
+				d.progress(d.written, d.res.Size)
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return false, nil
+		}
+
+		if readErr != nil {
+			return true, fmt.Errorf("failed to read response body: %w", readErr)
+		}
+	}
+}
+
+func (d *download) verify() error {
+	if d.res.Size >= 0 && d.written != d.res.Size {
+		return fmt.Errorf("%w: got %d bytes, expected %d",
+			ErrVerificationFailed, d.written, d.res.Size)
+	}
+
+	// OC uses MD5 checksums as ETags for files, anything else can't
+	// be verified.
+	want := strings.Trim(strings.TrimPrefix(d.res.ETag, "W/"), `"`)
+	if len(want) != md5.Size*2 {
+		return nil
+	}
+
+	if _, err := hex.DecodeString(want); err != nil {
+		return nil //nolint:nilerr
+	}
+
+	got := hex.EncodeToString(d.hash.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: got MD5 checksum %s, expected %s",
+			ErrVerificationFailed, got, want)
+	}
+
+	return nil
+}
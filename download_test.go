@@ -0,0 +1,177 @@
+package oc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestClient_DownloadFile(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	image := make([]byte, 256*1024)
+
+	_, _ = rand.New(rand.NewSource(1)).Read(image) //nolint:gosec
+
+	version := fake.AddVersion(testImageUUID, testImageVersion(string(image), "<newsItem/>"))
+
+	fake.Interrupts = 2
+	fake.InterruptAfter = 100 * 1024
+
+	dst := filepath.Join(t.TempDir(), "sample.jpeg")
+
+	var lastWritten, lastTotal int64
+
+	res, err := client.DownloadFile(context.Background(),
+		testImageUUID, "sample.jpeg", 0, dst, &oc.DownloadOptions{
+			Progress: func(written, total int64) {
+				lastWritten, lastTotal = written, total
+			},
+			ResumeDelay: time.Millisecond,
+		})
+	if err != nil {
+		t.Fatalf("failed to download file: %v", err)
+	}
+
+	got, err := os.ReadFile(dst) //nolint:gosec
+	if err != nil {
+		t.Fatalf("failed to read downloaded file: %v", err)
+	}
+
+	if !bytes.Equal(got, image) {
+		t.Error("the downloaded file doesn't match the original")
+	}
+
+	if res.Resumes != 2 {
+		t.Errorf("expected the download to be resumed twice, got %d", res.Resumes)
+	}
+
+	if res.Version != version || res.Size != int64(len(image)) {
+		t.Errorf("unexpected version %d and size %d", res.Version, res.Size)
+	}
+
+	if lastWritten != int64(len(image)) || lastTotal != int64(len(image)) {
+		t.Errorf("unexpected final progress %d/%d", lastWritten, lastTotal)
+	}
+}
+
+func TestClient_DownloadFile__ServerErrors(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image data", "<newsItem/>"))
+
+	fake.FileFailures = 2
+
+	dst := filepath.Join(t.TempDir(), "sample.jpeg")
+
+	res, err := client.DownloadFile(context.Background(),
+		testImageUUID, "sample.jpeg", 0, dst, &oc.DownloadOptions{
+			ResumeDelay: time.Millisecond,
+		})
+	if err != nil {
+		t.Fatalf("failed to download file: %v", err)
+	}
+
+	if res.Resumes != 2 {
+		t.Errorf("expected the download to be retried twice, got %d", res.Resumes)
+	}
+
+	got, err := os.ReadFile(dst) //nolint:gosec
+	if err != nil {
+		t.Fatalf("failed to read downloaded file: %v", err)
+	}
+
+	if string(got) != "image data" {
+		t.Errorf("unexpected downloaded data: %q", got)
+	}
+}
+
+func TestClient_DownloadFile__NoResumes(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image data", "<newsItem/>"))
+
+	fake.FileFailures = 1
+
+	dst := filepath.Join(t.TempDir(), "sample.jpeg")
+
+	_, err := client.DownloadFile(context.Background(),
+		testImageUUID, "sample.jpeg", 0, dst, &oc.DownloadOptions{
+			MaxResumes:  -1,
+			ResumeDelay: time.Millisecond,
+		})
+
+	var re *oc.ResponseError
+
+	if !errors.As(err, &re) || re.Response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a service unavailable error, got: %v", err)
+	}
+
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no file to be written, got: %v", err)
+	}
+}
+
+func TestClient_DownloadFile__Missing(t *testing.T) {
+	_, client := newFakeOC(t)
+
+	dir := t.TempDir()
+
+	_, err := client.DownloadFile(context.Background(),
+		testImageUUID, "sample.jpeg", 0, filepath.Join(dir, "sample.jpeg"), nil)
+	if err == nil {
+		t.Fatal("expected the download of a missing object to fail")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected temporary files to be cleaned up, found %d files", len(entries))
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	testCases := []struct {
+		header  string
+		want    oc.ContentRange
+		wantErr bool
+	}{
+		{"bytes 0-99/1000", oc.ContentRange{Start: 0, End: 99, Size: 1000}, false},
+		{"bytes 100-199/*", oc.ContentRange{Start: 100, End: 199, Size: -1}, false},
+		{"bytes */1000", oc.ContentRange{}, true},
+		{"items 0-1/2", oc.ContentRange{}, true},
+	}
+
+	for _, tc := range testCases {
+		got, err := oc.ParseContentRange(tc.header)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("expected %q to fail parsing", tc.header)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", tc.header, err)
+
+			continue
+		}
+
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParseContentRange(%q) mismatch (-want +got):\n%s", tc.header, diff)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package oc
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -13,6 +14,13 @@ import (
 
 const errorBodyCaptureLimit = 512
 
+// ErrConcurrentModification is returned when a conditional write
+// fails because the object was changed after it was read.
+var ErrConcurrentModification = errors.New("the object was modified concurrently")
+
+// ErrNotFound is returned when an object isn't found in the index.
+var ErrNotFound = errors.New("object not found")
+
 func safeClose(log log.Logger, name string, c io.Closer) {
 	err := c.Close()
 	if err != nil {
@@ -46,6 +54,15 @@ func (re *ResponseError) Error() string {
 	return "server responded with: " + re.Response.Status + ": " + re.message
 }
 
+// IsPreconditionFailed reports whether err is a response error for a
+// 412 Precondition Failed response, which OC returns when an If-Match
+// condition doesn't hold.
+func IsPreconditionFailed(err error) bool {
+	var re *ResponseError
+
+	return errors.As(err, &re) && re.Response.StatusCode == http.StatusPreconditionFailed
+}
+
 func printableWithCap(data []byte, maxLength int) string {
 	var b strings.Builder
 
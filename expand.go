@@ -0,0 +1,255 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const defaultExpandDepth = 3
+
+// ExpandSpec configures which relationships Expand follows.
+type ExpandSpec struct {
+	// Relations are the relationship properties to follow, like
+	// ConceptRelations. They're followed from every object in the
+	// graph that has them.
+	Relations []string
+	// MaxDepth is the maximum number of relationships between the
+	// root and any object in the graph. Defaults to three.
+	MaxDepth int
+	// Properties are fetched for every object in the graph.
+	Properties []string
+	Filters    string
+	Deleted    bool
+	// GetMany controls the lookups of each level of the graph.
+	GetMany *GetManyOptions
+}
+
+// Graph is an object and the objects that it's related to.
+type Graph struct {
+	Root  string
+	Nodes map[string]*GraphNode
+	// Missing are the related UUIDs that couldn't be fetched.
+	Missing []string
+}
+
+// GraphNode is an object in a graph.
+type GraphNode struct {
+	UUID    string
+	Version int
+	// Depth is the shortest distance from the root.
+	Depth      int
+	Properties Properties
+	Edges      []GraphEdge
+}
+
+// GraphEdge is a relationship between two objects. Cycle is set
+// for relationships that lead back to an object that the target was
+// reached through, following them never ends.
+type GraphEdge struct {
+	Relation string
+	To       string
+	Cycle    bool
+}
+
+// Node returns an object in the graph, nil if it isn't in the graph.
+func (g *Graph) Node(uuid string) *GraphNode {
+	return g.Nodes[uuid]
+}
+
+// Children returns the objects that an object is related to through
+// a relationship property, in relationship order. Objects that
+// weren't fetched are left out.
+func (g *Graph) Children(uuid string, relation string) []*GraphNode {
+	n := g.Nodes[uuid]
+	if n == nil {
+		return nil
+	}
+
+	var res []*GraphNode
+
+	for _, e := range n.Edges {
+		if e.Relation != relation {
+			continue
+		}
+
+		if child := g.Nodes[e.To]; child != nil {
+			res = append(res, child)
+		}
+	}
+
+	return res
+}
+
+// HasCycles reports whether the graph has any cyclic relationships.
+func (g *Graph) HasCycles() bool {
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			if e.Cycle {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Expand fetches an object and follows its relationships recursively,
+// one Get lookup per level of the graph. Objects that are reached
+// more than once are only fetched once.
+func (c *Client) Expand(ctx context.Context, uuid string, spec ExpandSpec) (*Graph, error) {
+	if uuid == "" {
+		return nil, errors.New("missing UUID to expand")
+	}
+
+	maxDepth := spec.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultExpandDepth
+	}
+
+	graph := Graph{
+		Root:  uuid,
+		Nodes: make(map[string]*GraphNode),
+	}
+
+	visited := map[string]bool{uuid: true}
+	level := []string{uuid}
+
+	for depth := 0; len(level) > 0; depth++ {
+		// There's no need to follow the relationships of the last
+		// level.
+		follow := depth < maxDepth
+
+		props, err := expandProperties(spec, follow)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.GetMany(ctx, GetRequest{
+			UUIDs:      level,
+			Properties: props,
+			Filters:    spec.Filters,
+			Deleted:    spec.Deleted,
+		}, spec.GetMany)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get level %d of the graph: %w", depth, err)
+		}
+
+		if depth == 0 && len(res.Hits) == 0 {
+			return nil, fmt.Errorf("object %s: %w", uuid, ErrNotFound)
+		}
+
+		graph.Missing = append(graph.Missing, res.Missing...)
+
+		var next []string
+
+		for _, hit := range res.Hits {
+			node := GraphNode{
+				UUID:       hit.ID,
+				Version:    hit.Version,
+				Depth:      depth,
+				Properties: hit.Properties,
+			}
+
+			if follow {
+				node.Edges = relationEdges(hit.Properties, spec.Relations)
+			}
+
+			for _, e := range node.Edges {
+				if !visited[e.To] {
+					visited[e.To] = true
+					next = append(next, e.To)
+				}
+			}
+
+			graph.Nodes[hit.ID] = &node
+		}
+
+		level = next
+	}
+
+	graph.markCycles()
+
+	return &graph, nil
+}
+
+func expandProperties(spec ExpandSpec, follow bool) (string, error) {
+	var pl PropertyList
+
+	pl.Append(spec.Properties...)
+
+	if follow {
+		for _, rel := range spec.Relations {
+			pl.Ensure(rel, "uuid")
+		}
+	}
+
+	if len(pl) == 0 {
+		return "", nil
+	}
+
+	text, err := pl.MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("invalid properties: %w", err)
+	}
+
+	return string(text), nil
+}
+
+func relationEdges(p Properties, relations []string) []GraphEdge {
+	var edges []GraphEdge
+
+	for _, rel := range relations {
+		related, ok := p.Relationships(rel)
+		if !ok {
+			continue
+		}
+
+		for _, r := range related {
+			id, ok := r.Get("uuid")
+			if !ok || id == "" {
+				continue
+			}
+
+			edges = append(edges, GraphEdge{Relation: rel, To: id})
+		}
+	}
+
+	return edges
+}
+
+// markCycles flags the edges that lead back to an object on the path
+// from the root.
+func (g *Graph) markCycles() {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+
+	state := make(map[string]int, len(g.Nodes))
+
+	var visit func(id string)
+
+	visit = func(id string) {
+		n := g.Nodes[id]
+		if n == nil {
+			return
+		}
+
+		state[id] = onPath
+
+		for i := range n.Edges {
+			switch state[n.Edges[i].To] {
+			case onPath:
+				n.Edges[i].Cycle = true
+			case unvisited:
+				visit(n.Edges[i].To)
+			}
+		}
+
+		state[id] = done
+	}
+
+	visit(g.Root)
+}
@@ -0,0 +1,105 @@
+package oc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestClient_Expand(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	add := func(id string, relations map[string][]string) {
+		v := testImageVersion("image", "metadata")
+		v.Relations = relations
+
+		fake.AddVersion(id, v)
+	}
+
+	add("article", map[string][]string{
+		"ConceptRelations": {"c1", "c2"},
+		"ImageRelations":   {"image", "gone"},
+		"Ignored":          {"other"},
+	})
+	add("c1", map[string][]string{"ConceptArticleRelations": {"article"}})
+	add("c2", map[string][]string{"ConceptRelations": {"c3", "c1"}})
+	add("c3", map[string][]string{"ConceptRelations": {"c4"}})
+	add("c4", map[string][]string{"ConceptRelations": {"c5"}})
+	add("c5", nil)
+	add("image", nil)
+	add("other", nil)
+
+	graph, err := client.Expand(context.Background(), "article", oc.ExpandSpec{
+		Relations:  []string{"ConceptRelations", "ImageRelations", "ConceptArticleRelations"},
+		MaxDepth:   3,
+		Properties: []string{"uuid"},
+	})
+	if err != nil {
+		t.Fatalf("failed to expand graph: %v", err)
+	}
+
+	depths := make(map[string]int)
+
+	for id, n := range graph.Nodes {
+		depths[id] = n.Depth
+	}
+
+	wantDepths := map[string]int{
+		"article": 0, "c1": 1, "c2": 1, "image": 1, "c3": 2, "c4": 3,
+	}
+
+	if diff := cmp.Diff(wantDepths, depths); diff != "" {
+		t.Errorf("nodes mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"gone"}, graph.Missing); diff != "" {
+		t.Errorf("missing mismatch (-want +got):\n%s", diff)
+	}
+
+	if n := len(fake.GetRequests); n != 4 {
+		t.Errorf("expected one get request per level, got %d", n)
+	}
+
+	var concepts []string
+
+	for _, n := range graph.Children("article", "ConceptRelations") {
+		concepts = append(concepts, n.UUID)
+	}
+
+	if diff := cmp.Diff([]string{"c1", "c2"}, concepts); diff != "" {
+		t.Errorf("children mismatch (-want +got):\n%s", diff)
+	}
+
+	if !graph.HasCycles() {
+		t.Fatal("expected the graph to have a cycle")
+	}
+
+	back := graph.Node("c1").Edges[0]
+	if !back.Cycle || back.To != "article" {
+		t.Errorf("expected the edge from c1 to the article to be a cycle, got %+v", back)
+	}
+
+	for _, e := range graph.Node("c2").Edges {
+		if e.Cycle {
+			t.Errorf("unexpected cycle on edge %+v", e)
+		}
+	}
+
+	if len(graph.Node("c4").Edges) != 0 {
+		t.Error("expected no edges beyond the max depth")
+	}
+}
+
+func TestClient_Expand_NotFound(t *testing.T) {
+	_, client := newFakeOC(t)
+
+	_, err := client.Expand(context.Background(), "nope", oc.ExpandSpec{
+		Relations: []string{"ConceptRelations"},
+	})
+	if !errors.Is(err, oc.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package oc
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FacetSort is the order of the terms of a field facet.
+type FacetSort int
+
+const (
+	// FacetSortCount sorts terms by descending frequency.
+	FacetSortCount FacetSort = iota
+	// FacetSortIndex sorts terms in index order, alphabetically for
+	// strings.
+	FacetSortIndex
+)
+
+func (fs FacetSort) String() string {
+	switch fs {
+	case FacetSortCount:
+		return "count"
+	case FacetSortIndex:
+		return "index"
+	default:
+		return "unknown"
+	}
+}
+
+// FacetGap is the size of the buckets of a date facet.
+type FacetGap int
+
+const (
+	FacetGapDay FacetGap = iota
+	FacetGapMonth
+	FacetGapYear
+	FacetGapHour
+)
+
+func (fg FacetGap) String() string {
+	switch fg {
+	case FacetGapDay:
+		return "+1DAY"
+	case FacetGapMonth:
+		return "+1MONTH"
+	case FacetGapYear:
+		return "+1YEAR"
+	case FacetGapHour:
+		return "+1HOUR"
+	default:
+		return "unknown"
+	}
+}
+
+// FieldFacet requests the term frequencies of an index field.
+type FieldFacet struct {
+	Field string
+	// Prefix limits the facet to terms starting with the prefix.
+	Prefix string
+	Sort   FacetSort
+	// Limit and MinCount override the limit and minimum count of
+	// SearchFacets for this field when set.
+	Limit    int
+	MinCount int
+}
+
+// DateFacet requests the number of hits per time period for a date
+// index field. A zero Start or End leaves the range to OC.
+type DateFacet struct {
+	Field string
+	Start time.Time
+	End   time.Time
+	Gap   FacetGap
+}
+
+// QueryFacet requests the number of hits that match a query.
+type QueryFacet struct {
+	Query string
+}
+
+func (sf *SearchFacets) addQueryValues(q url.Values) {
+	fields := append([]string(nil), sf.Fields...)
+
+	for _, f := range sf.FieldFacets {
+		fields = append(fields, f.Field)
+
+		if f.Prefix != "" {
+			q.Set("facet."+f.Field+".prefix", f.Prefix)
+		}
+
+		if f.Sort != FacetSortCount {
+			q.Set("facet."+f.Field+".sort", f.Sort.String())
+		}
+
+		if f.Limit > 0 {
+			q.Set("facet."+f.Field+".limit", strconv.Itoa(f.Limit))
+		}
+
+		if f.MinCount > 0 {
+			q.Set("facet."+f.Field+".mincount", strconv.Itoa(f.MinCount))
+		}
+	}
+
+	if len(fields) > 0 {
+		q.Set("facet.indexfield", strings.Join(fields, "\n"))
+	}
+
+	for _, f := range sf.DateFacets {
+		q.Add("facet.date.indexfield", f.Field)
+
+		if !f.Start.IsZero() {
+			q.Set("facet.date."+f.Field+".start", FormatQueryDate(f.Start))
+		}
+
+		if !f.End.IsZero() {
+			q.Set("facet.date."+f.Field+".end", FormatQueryDate(f.End))
+		}
+
+		q.Set("facet.date."+f.Field+".gap", f.Gap.String())
+	}
+
+	for _, f := range sf.Queries {
+		q.Add("facet.query", f.Query)
+	}
+
+	if len(fields) == 0 && len(sf.DateFacets) == 0 && len(sf.Queries) == 0 {
+		return
+	}
+
+	if sf.Limit > 0 {
+		q.Set("facet.limit", strconv.Itoa(sf.Limit))
+	}
+
+	if sf.MinCount > 0 {
+		q.Set("facet.mincount", strconv.Itoa(sf.MinCount))
+	}
+}
+
+// DateFacetField is the result of a date facet.
+type DateFacetField struct {
+	FacetField  string      `json:"facetField"`
+	Gap         string      `json:"gap"`
+	Frequencies []Frequency `json:"frequencies"`
+}
+
+// DateBucket is the number of hits in the time period starting at
+// Start.
+type DateBucket struct {
+	Start time.Time
+	Count int
+}
+
+// Buckets returns the buckets of the date facet ordered by time.
+// Terms that aren't valid RFC 3339 timestamps are skipped.
+func (df DateFacetField) Buckets() []DateBucket {
+	buckets := make([]DateBucket, 0, len(df.Frequencies))
+
+	for _, f := range df.Frequencies {
+		t, err := time.Parse(time.RFC3339, f.Term)
+		if err != nil {
+			continue
+		}
+
+		buckets = append(buckets, DateBucket{Start: t, Count: f.Frequency})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+
+	return buckets
+}
+
+// QueryFacetResult is the number of hits that matched a facet query.
+type QueryFacetResult struct {
+	Query     string `json:"query"`
+	Frequency int    `json:"frequency"`
+}
+
+// Field returns the facet result for an index field.
+func (ff FacetFields) Field(name string) (FacetField, bool) {
+	for _, f := range ff.Fields {
+		if f.FacetField == name {
+			return f, true
+		}
+	}
+
+	return FacetField{}, false
+}
+
+// Frequencies returns the term frequencies of an index field facet
+// as a map, nil if there's no facet for the field.
+func (ff FacetFields) Frequencies(name string) map[string]int {
+	f, ok := ff.Field(name)
+	if !ok {
+		return nil
+	}
+
+	return f.Map()
+}
+
+// DateField returns the date facet result for an index field.
+func (ff FacetFields) DateField(name string) (DateFacetField, bool) {
+	for _, f := range ff.DateFields {
+		if f.FacetField == name {
+			return f, true
+		}
+	}
+
+	return DateFacetField{}, false
+}
+
+// Query returns the number of hits that matched a facet query.
+func (ff FacetFields) Query(query string) (int, bool) {
+	for _, q := range ff.Queries {
+		if q.Query == query {
+			return q.Frequency, true
+		}
+	}
+
+	return 0, false
+}
+
+// Map returns the term frequencies as a map.
+func (f FacetField) Map() map[string]int {
+	m := make(map[string]int, len(f.Frequencies))
+
+	for _, freq := range f.Frequencies {
+		m[freq.Term] = freq.Frequency
+	}
+
+	return m
+}
+
+// Date returns the period of a facet result that has its Year, Month
+// and Day set, with missing parts defaulting to the start of the
+// period.
+func (f FacetField) Date() (time.Time, bool) {
+	if f.Year == 0 {
+		return time.Time{}, false
+	}
+
+	month, day := f.Month, f.Day
+
+	if month == 0 {
+		month = 1
+	}
+
+	if day == 0 {
+		day = 1
+	}
+
+	return time.Date(f.Year, time.Month(month), day, 0, 0, 0, 0, time.UTC), true
+}
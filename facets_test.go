@@ -0,0 +1,127 @@
+package oc_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestSearchFacets_QueryValues(t *testing.T) {
+	req := oc.SearchRequest{
+		Facets: oc.SearchFacets{
+			Fields:   []string{"ConceptTopics"},
+			Limit:    10,
+			MinCount: 2,
+			FieldFacets: []oc.FieldFacet{
+				{Field: "ObjectCreator", Prefix: "An", Sort: oc.FacetSortIndex, Limit: 50},
+			},
+			DateFacets: []oc.DateFacet{{
+				Field: "created",
+				Start: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				Gap:   oc.FacetGapMonth,
+			}},
+			Queries: []oc.QueryFacet{{Query: "ArticleMetaNewsValue:[4 TO 6]"}},
+		},
+	}
+
+	q, err := req.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := url.Values{
+		"facet.indexfield":           {"ConceptTopics\nObjectCreator"},
+		"facet.limit":                {"10"},
+		"facet.mincount":             {"2"},
+		"facet.ObjectCreator.prefix": {"An"},
+		"facet.ObjectCreator.sort":   {"index"},
+		"facet.ObjectCreator.limit":  {"50"},
+		"facet.date.indexfield":      {"created"},
+		"facet.date.created.start":   {"2020-01-01T00:00:00Z"},
+		"facet.date.created.end":     {"2021-01-01T00:00:00Z"},
+		"facet.date.created.gap":     {"+1MONTH"},
+		"facet.query":                {"ArticleMetaNewsValue:[4 TO 6]"},
+		"start":                      {"0"},
+		"limit":                      {"15"},
+	}
+
+	if diff := cmp.Diff(want, q); diff != "" {
+		t.Errorf("QueryValues() mismatch (-want +got):\n%s", diff)
+	}
+
+	q, err = (&oc.SearchRequest{Facets: oc.SearchFacets{Limit: 10}}).QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Has("facet.limit") {
+		t.Error("expected no facet parameters without facets")
+	}
+}
+
+func TestFacetFields(t *testing.T) {
+	data := []byte(`{
+  "fields": [
+    {"facetField": "ConceptTopics", "frequencies": [
+      {"term": "sports", "frequency": 12},
+      {"term": "politics", "frequency": 7}
+    ]},
+    {"facetField": "created", "year": 2021, "month": 3, "frequencies": []}
+  ],
+  "dateFields": [
+    {"facetField": "created", "gap": "+1MONTH", "frequencies": [
+      {"term": "2020-02-01T00:00:00Z", "frequency": 4},
+      {"term": "2020-01-01T00:00:00Z", "frequency": 9},
+      {"term": "garbage", "frequency": 1}
+    ]}
+  ],
+  "queries": [
+    {"query": "ArticleMetaNewsValue:[4 TO 6]", "frequency": 3}
+  ]
+}`)
+
+	var facets oc.FacetFields
+
+	if err := json.Unmarshal(data, &facets); err != nil {
+		t.Fatalf("failed to unmarshal facets: %v", err)
+	}
+
+	if diff := cmp.Diff(map[string]int{"sports": 12, "politics": 7},
+		facets.Frequencies("ConceptTopics")); diff != "" {
+		t.Errorf("Frequencies() mismatch (-want +got):\n%s", diff)
+	}
+
+	if facets.Frequencies("Nope") != nil {
+		t.Error("expected no frequencies for a missing facet")
+	}
+
+	dates, ok := facets.DateField("created")
+	if !ok {
+		t.Fatal("expected a date facet for created")
+	}
+
+	wantBuckets := []oc.DateBucket{
+		{Start: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Count: 9},
+		{Start: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), Count: 4},
+	}
+
+	if diff := cmp.Diff(wantBuckets, dates.Buckets()); diff != "" {
+		t.Errorf("Buckets() mismatch (-want +got):\n%s", diff)
+	}
+
+	if n, ok := facets.Query("ArticleMetaNewsValue:[4 TO 6]"); !ok || n != 3 {
+		t.Errorf("unexpected query facet result %d, %v", n, ok)
+	}
+
+	created, _ := facets.Field("created")
+
+	date, ok := created.Date()
+	if !ok || !date.Equal(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected facet date %v, %v", date, ok)
+	}
+}
@@ -0,0 +1,615 @@
+package oc_test
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+// fakeOC is a minimal in-memory stand-in for the Open Content object
+// API. It keeps every version of every object so that tests can
+// exercise version aware client operations.
+type fakeOC struct {
+	m       sync.Mutex
+	objects map[string][]*fakeVersion
+	events  []oc.EventlogEvent
+
+	// URL is the base URL of the server.
+	URL string
+
+	// Requests counts the number of requests made to the server.
+	Requests int
+	// Uploads counts the number of accepted uploads.
+	Uploads int
+	// UploadFailures is the number of uploads that should fail with
+	// UploadFailureStatus, 503 Service Unavailable by default.
+	UploadFailures      int
+	UploadFailureStatus int
+
+	// Interrupts is the number of file responses that should be
+	// cut off after InterruptAfter bytes.
+	Interrupts     int
+	InterruptAfter int
+
+	// Schema is served by the contenttypes endpoint, and
+	// ConfigChecksum as the active configuration checksum by the
+	// health endpoint.
+	Schema         *oc.ContentTypesResponse
+	ConfigChecksum string
+	// SchemaLoads counts the number of contenttypes requests.
+	SchemaLoads int
+
+	// GetRequests records the uuid parameter of get requests.
+	GetRequests []string
+	// GetFailures is the number of get requests that should fail
+	// with a 500 Internal Server Error response.
+	GetFailures int
+	// FileFailures is the number of file requests that should fail
+	// with a 503 Service Unavailable response.
+	FileFailures int
+}
+
+type fakeVersion struct {
+	Unit        string
+	Source      string
+	Batch       bool
+	Primary     string
+	Metadata    []string
+	Preview     string
+	Thumb       string
+	Files       map[string]fakeFile
+	ContentType string
+	Properties  []oc.Property
+	// Relations are the UUIDs of related objects by relationship
+	// property, returned by get requests.
+	Relations map[string][]string
+}
+
+// properties returns the properties of the version together with the
+// Source and Unit system properties.
+func (v *fakeVersion) properties() []oc.Property {
+	props := append([]oc.Property(nil), v.Properties...)
+
+	for _, p := range []oc.Property{
+		{Name: "Source", Type: "STRING", ReadOnly: true, Values: []oc.PropertyValue{{Value: v.Source}}},
+		{Name: "Unit", Type: "STRING", ReadOnly: true, Values: []oc.PropertyValue{{Value: v.Unit}}},
+	} {
+		if p.Values[0].Value != "" {
+			props = append(props, p)
+		}
+	}
+
+	return props
+}
+
+type fakeFile struct {
+	Mimetype string
+	Data     []byte
+}
+
+func (v *fakeVersion) etag() string {
+	hash := md5.New() //nolint:gosec
+
+	for _, name := range v.names() {
+		sum := md5.Sum(v.Files[name].Data) //nolint:gosec
+
+		_, _ = hash.Write([]byte(hex.EncodeToString(sum[:])))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (v *fakeVersion) names() []string {
+	names := []string{v.Primary}
+
+	names = append(names, v.Metadata...)
+
+	for _, n := range []string{v.Preview, v.Thumb} {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
+
+func newFakeOC(t *testing.T) (*fakeOC, *oc.Client) {
+	t.Helper()
+
+	f := &fakeOC{
+		objects: make(map[string][]*fakeVersion),
+	}
+
+	ts := httptest.NewServer(f)
+
+	t.Cleanup(ts.Close)
+
+	f.URL = ts.URL
+
+	client, err := oc.New(oc.Options{
+		BaseURL:    ts.URL,
+		HTTPClient: ts.Client(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create OC client: %v", err)
+	}
+
+	return f, client
+}
+
+// AddVersion stores a new version of an object and returns the new
+// version number.
+func (f *fakeOC) AddVersion(id string, v *fakeVersion) int64 {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.addVersion(id, v)
+}
+
+func (f *fakeOC) addVersion(id string, v *fakeVersion) int64 {
+	f.objects[id] = append(f.objects[id], v)
+
+	version := len(f.objects[id])
+
+	eventType := oc.EventUpdate
+	if version == 1 {
+		eventType = oc.EventAdd
+	}
+
+	f.addEvent(id, eventType, version)
+
+	return int64(version)
+}
+
+func (f *fakeOC) addEvent(id string, eventType string, version int) {
+	event := oc.EventlogEvent{
+		ID:        len(f.events) + 1,
+		UUID:      id,
+		EventType: eventType,
+		Created:   time.Now(),
+	}
+
+	event.Content.UUID = id
+	event.Content.Version = version
+
+	f.events = append(f.events, event)
+}
+
+// Delete removes an object.
+func (f *fakeOC) Delete(id string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	delete(f.objects, id)
+	f.addEvent(id, oc.EventDelete, 0)
+}
+
+// Purge removes an old version of an object, like OC does when
+// versions are pruned by retention.
+func (f *fakeOC) Purge(id string, version int64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.objects[id][version-1] = nil
+}
+
+// Version returns a stored version of an object, version 0 returns
+// the current version.
+func (f *fakeOC) Version(id string, version int64) (*fakeVersion, int64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.version(id, version)
+}
+
+func (f *fakeOC) version(id string, version int64) (*fakeVersion, int64) {
+	versions := f.objects[id]
+
+	if version == 0 {
+		version = int64(len(versions))
+	}
+
+	if version < 1 || version > int64(len(versions)) {
+		return nil, 0
+	}
+
+	return versions[version-1], version
+}
+
+func (f *fakeOC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.Requests++
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(path) == 1 && path[0] == "objectupload":
+		f.upload(w, r)
+	case len(path) == 1 && path[0] == "eventlog":
+		f.eventlog(w, r)
+	case len(path) == 1 && path[0] == "get":
+		f.get(w, r)
+	case len(path) == 1 && path[0] == "health":
+		var res oc.Health
+
+		res.ActiveConfiguration.Checksum = f.ConfigChecksum
+
+		writeFakeJSON(w, res)
+	case len(path) == 1 && path[0] == "contenttypes" && f.Schema != nil:
+		f.SchemaLoads++
+
+		writeFakeJSON(w, f.Schema)
+	case len(path) == 2 && path[0] == "objects" && r.Method == http.MethodDelete:
+		if _, ok := f.objects[path[1]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		delete(f.objects, path[1])
+		f.addEvent(path[1], oc.EventDelete, 0)
+	case len(path) >= 2 && path[0] == "objects":
+		f.object(w, r, path[1], path[2:])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeOC) eventlog(w http.ResponseWriter, r *http.Request) {
+	after, _ := strconv.Atoi(r.URL.Query().Get("event"))
+
+	var res struct {
+		Events []oc.EventlogEvent `json:"events"`
+	}
+
+	for _, e := range f.events {
+		if e.ID > after {
+			res.Events = append(res.Events, e)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (f *fakeOC) object(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	var version int64
+
+	if vs := r.URL.Query().Get("version"); vs != "" {
+		version, _ = strconv.ParseInt(vs, 10, 64)
+	}
+
+	v, version := f.version(id, version)
+	if v == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("X-Opencontent-Object-Version", strconv.FormatInt(version, 10))
+
+	switch {
+	case r.Method == http.MethodPut && len(rest) == 3 && rest[1] == "metadata":
+		f.replaceMetadata(w, r, id, v, rest[2])
+	case len(rest) == 0:
+		f.serveFile(w, r, v.etag(), v.Files[v.Primary])
+	case len(rest) == 1 && rest[0] == "files":
+		f.listFiles(w, v)
+	case len(rest) == 1 && rest[0] == "properties":
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(oc.PropertyResult{
+			ContentType: v.ContentType,
+			Properties:  v.properties(),
+		})
+	case len(rest) == 2 && rest[0] == "files" && rest[1] == "metadata":
+		if len(v.Metadata) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		f.serveFile(w, r, v.etag(), v.Files[v.Metadata[0]])
+	case len(rest) == 2 && rest[0] == "files" && f.FileFailures > 0:
+		f.FileFailures--
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case len(rest) == 2 && rest[0] == "files":
+		file, ok := v.Files[rest[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		sum := md5.Sum(file.Data) //nolint:gosec
+
+		f.serveFile(w, r, `"`+hex.EncodeToString(sum[:])+`"`, file)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeOC) serveFile(w http.ResponseWriter, r *http.Request, etag string, file fakeFile) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", file.Mimetype)
+
+	if r.Method != http.MethodHead && f.Interrupts > 0 {
+		f.Interrupts--
+
+		w = &interruptingWriter{ResponseWriter: w, remaining: f.InterruptAfter}
+	}
+
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(file.Data))
+}
+
+// interruptingWriter aborts the response after a number of bytes
+// have been written.
+type interruptingWriter struct {
+	http.ResponseWriter
+
+	remaining int
+}
+
+func (iw *interruptingWriter) Write(data []byte) (int, error) {
+	if len(data) > iw.remaining {
+		_, _ = iw.ResponseWriter.Write(data[:iw.remaining])
+
+		iw.ResponseWriter.(http.Flusher).Flush()
+
+		panic(http.ErrAbortHandler)
+	}
+
+	iw.remaining -= len(data)
+
+	return iw.ResponseWriter.Write(data) //nolint:wrapcheck
+}
+
+func (f *fakeOC) replaceMetadata(
+	w http.ResponseWriter, r *http.Request, id string, current *fakeVersion, name string,
+) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && current.etag() != ifMatch {
+		http.Error(w, "ETag mismatch", http.StatusPreconditionFailed)
+		return
+	}
+
+	if !slices.Contains(current.Metadata, name) {
+		http.Error(w, "no such metadata file", http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	v := *current
+
+	v.Files = maps.Clone(current.Files)
+	v.Files[name] = fakeFile{
+		Mimetype: r.Header.Get("Content-Type"),
+		Data:     data,
+	}
+
+	version := f.addVersion(id, &v)
+
+	w.Header().Set("ETag", v.etag())
+	w.Header().Set("X-Opencontent-Object-Version", strconv.FormatInt(version, 10))
+}
+
+func (f *fakeOC) listFiles(w http.ResponseWriter, v *fakeVersion) {
+	objFile := func(name string) oc.ObjectFile {
+		if name == "" {
+			return oc.ObjectFile{}
+		}
+
+		return oc.ObjectFile{Name: name, Mimetype: v.Files[name].Mimetype}
+	}
+
+	list := oc.FileList{
+		Primary: objFile(v.Primary),
+		Preview: objFile(v.Preview),
+		Thumb:   objFile(v.Thumb),
+	}
+
+	for _, name := range v.Metadata {
+		list.Metadata = append(list.Metadata, objFile(name))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+func (f *fakeOC) upload(w http.ResponseWriter, r *http.Request) {
+	if f.UploadFailures > 0 {
+		f.UploadFailures--
+
+		status := f.UploadFailureStatus
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+
+		http.Error(w, "try again later", status)
+
+		return
+	}
+
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	form := r.MultipartForm
+
+	id := formValue(form.Value, "id")
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	current, _ := f.version(id, 0)
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && (current == nil || current.etag() != ifMatch) {
+		http.Error(w, "ETag mismatch", http.StatusPreconditionFailed)
+		return
+	}
+
+	v := fakeVersion{
+		Unit:   r.Header.Get("X-Imid-Unit"),
+		Source: formValue(form.Value, "source"),
+		Batch:  formValue(form.Value, "batch") == "true",
+		Files:  make(map[string]fakeFile),
+	}
+
+	fields := make([]string, 0, len(form.Value))
+
+	for field := range form.Value {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if field == "id" || field == "source" || field == "batch" ||
+			strings.HasSuffix(field, "-mimetype") {
+			continue
+		}
+
+		name := formValue(form.Value, field)
+
+		headers := form.File[name]
+		if len(headers) == 0 {
+			http.Error(w, fmt.Sprintf("missing file %q", name), http.StatusBadRequest)
+			return
+		}
+
+		data, err := readFormFile(headers[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		v.Files[name] = fakeFile{
+			Mimetype: formValue(form.Value, field+"-mimetype"),
+			Data:     data,
+		}
+
+		switch {
+		case field == "file":
+			v.Primary = name
+		case field == "preview":
+			v.Preview = name
+		case field == "thumb":
+			v.Thumb = name
+		case strings.HasPrefix(field, "metadata"):
+			v.Metadata = append(v.Metadata, name)
+		}
+	}
+
+	if v.Primary == "" {
+		http.Error(w, "A file without metadata can't be imported", http.StatusBadRequest)
+		return
+	}
+
+	if len(v.Metadata) > 0 && formValue(form.Value, "metadata") == "" {
+		http.Error(w, "metadata files must start with the metadata field", http.StatusBadRequest)
+		return
+	}
+
+	version := f.addVersion(id, &v)
+	f.Uploads++
+
+	w.Header().Set("ETag", v.etag())
+	w.Header().Set("X-Opencontent-Object-Version", strconv.FormatInt(version, 10))
+
+	_, _ = io.WriteString(w, id)
+}
+
+func formValue(values map[string][]string, name string) string {
+	if len(values[name]) == 0 {
+		return ""
+	}
+
+	return values[name][0]
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	defer file.Close() //nolint:errcheck
+
+	return io.ReadAll(file) //nolint:wrapcheck
+}
+
+func writeFakeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeOC) get(w http.ResponseWriter, r *http.Request) {
+	param := r.URL.Query().Get("uuid")
+
+	f.GetRequests = append(f.GetRequests, param)
+
+	if f.GetFailures > 0 {
+		f.GetFailures--
+
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	var res oc.GetResponse
+
+	for _, id := range strings.Split(param, ",") {
+		versions, ok := f.objects[id]
+		if !ok {
+			continue
+		}
+
+		props := oc.Properties{"uuid": {id}}
+
+		for rel, targets := range versions[len(versions)-1].Relations {
+			for _, target := range targets {
+				props[rel] = append(props[rel], map[string]interface{}{
+					"uuid": []interface{}{target},
+				})
+			}
+		}
+
+		res.Hits.Items = append(res.Hits.Items, oc.Hit{
+			ID:         id,
+			Version:    len(versions),
+			Properties: props,
+		})
+	}
+
+	res.Hits.TotalHits = len(res.Hits.Items)
+	res.Hits.IncludedHits = len(res.Hits.Items)
+
+	writeFakeJSON(w, res)
+}
@@ -0,0 +1,194 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+const (
+	defaultGetManyConcurrency = 4
+	// defaultGetManyParamLength keeps the URL well below the 8KiB
+	// request line limit of common servers and proxies, leaving
+	// room for the base URL and the other parameters.
+	defaultGetManyParamLength = 4000
+)
+
+// GetManyOptions controls how GetMany splits and runs its requests.
+type GetManyOptions struct {
+	// Concurrency is the number of requests that run in parallel.
+	// Defaults to four.
+	Concurrency int
+	// MaxParamLength is the maximum length of the URL encoded uuid
+	// parameter of a single request. Defaults to 4000.
+	MaxParamLength int
+}
+
+// GetManyResponse is the merged result of a GetMany call.
+type GetManyResponse struct {
+	// Hits are in the order of the requested UUIDs.
+	Hits []Hit
+	// Missing are the requested UUIDs that weren't returned by OC,
+	// either because they don't exist in the index or because they
+	// were excluded by the filters.
+	Missing []string
+}
+
+// GetMany is Get for any number of UUIDs. The UUIDs are split into
+// chunks that keep the request URLs short enough, and the chunks are
+// fetched concurrently. Duplicate UUIDs are only fetched once. If a
+// request fails the remaining requests are cancelled and the error is
+// returned.
+func (c *Client) GetMany(ctx context.Context, req GetRequest, opts *GetManyOptions) (*GetManyResponse, error) {
+	if len(req.UUIDs) == 0 {
+		return nil, errors.New("missing UUIDs to get")
+	}
+
+	var options GetManyOptions
+
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultGetManyConcurrency
+	}
+
+	if options.MaxParamLength <= 0 {
+		options.MaxParamLength = defaultGetManyParamLength
+	}
+
+	uuids := uniqueStrings(req.UUIDs)
+	chunks := chunkUUIDs(uuids, options.MaxParamLength)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		m        sync.Mutex
+		firstErr error
+		hits     = make(map[string]Hit, len(uuids))
+		work     = make(chan []string)
+	)
+
+	for i := 0; i < options.Concurrency && i < len(chunks); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for chunk := range work {
+				chunkReq := req
+				chunkReq.UUIDs = chunk
+
+				res, err := c.Get(ctx, chunkReq)
+
+				m.Lock()
+
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("failed to get %d objects: %w", len(chunk), err)
+
+					cancel()
+				}
+
+				if err == nil {
+					for _, hit := range res.Hits.Items {
+						hits[hit.ID] = hit
+					}
+				}
+
+				m.Unlock()
+			}
+		}()
+	}
+
+	for _, chunk := range chunks {
+		select {
+		case work <- chunk:
+		case <-ctx.Done():
+		}
+	}
+
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	resp := GetManyResponse{
+		Hits: make([]Hit, 0, len(hits)),
+	}
+
+	for _, id := range uuids {
+		hit, ok := hits[id]
+		if !ok {
+			resp.Missing = append(resp.Missing, id)
+
+			continue
+		}
+
+		resp.Hits = append(resp.Hits, hit)
+	}
+
+	return &resp, nil
+}
+
+// chunkUUIDs splits UUIDs into chunks where the URL encoded, comma
+// separated, list is at most maxLength long. A UUID that is longer
+// than that on its own gets a chunk of its own.
+func chunkUUIDs(uuids []string, maxLength int) [][]string {
+	var (
+		chunks [][]string
+		chunk  []string
+		length int
+	)
+
+	for _, id := range uuids {
+		n := len(url.QueryEscape(id))
+
+		if len(chunk) > 0 {
+			// Account for the escaped comma separator.
+			n += len(url.QueryEscape(","))
+		}
+
+		if len(chunk) > 0 && length+n > maxLength {
+			chunks = append(chunks, chunk)
+			chunk, length = nil, 0
+			n = len(url.QueryEscape(id))
+		}
+
+		chunk = append(chunk, id)
+		length += n
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	res := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+
+		seen[v] = true
+
+		res = append(res, v)
+	}
+
+	return res
+}
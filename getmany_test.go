@@ -0,0 +1,106 @@
+package oc_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestClient_GetMany(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	var uuids []string
+
+	missing := make(map[string]bool)
+
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("00000000-0000-0000-0000-%012d", i)
+
+		uuids = append(uuids, id)
+
+		// Every tenth object is missing from the index.
+		if i%10 == 3 {
+			missing[id] = true
+
+			continue
+		}
+
+		fake.AddVersion(id, testImageVersion("image", "metadata"))
+	}
+
+	// Request in reverse order, with a duplicate.
+	var request []string
+
+	for i := len(uuids) - 1; i >= 0; i-- {
+		request = append(request, uuids[i])
+	}
+
+	request = append(request, uuids[0])
+
+	const maxLength = 500
+
+	res, err := client.GetMany(context.Background(), oc.GetRequest{
+		UUIDs:      request,
+		Properties: "uuid",
+	}, &oc.GetManyOptions{
+		Concurrency:    3,
+		MaxParamLength: maxLength,
+	})
+	if err != nil {
+		t.Fatalf("failed to get objects: %v", err)
+	}
+
+	var (
+		wantHits    []string
+		wantMissing []string
+	)
+
+	for _, id := range request[:len(request)-1] {
+		if missing[id] {
+			wantMissing = append(wantMissing, id)
+		} else {
+			wantHits = append(wantHits, id)
+		}
+	}
+
+	gotHits := make([]string, len(res.Hits))
+
+	for i, hit := range res.Hits {
+		gotHits[i] = hit.ID
+	}
+
+	if diff := cmp.Diff(wantHits, gotHits); diff != "" {
+		t.Errorf("hits mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(wantMissing, res.Missing); diff != "" {
+		t.Errorf("missing mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(fake.GetRequests) < 2 {
+		t.Errorf("expected the request to be split, got %d requests", len(fake.GetRequests))
+	}
+
+	for _, param := range fake.GetRequests {
+		if n := len(url.QueryEscape(param)); n > maxLength {
+			t.Errorf("uuid parameter of %d bytes exceeds the limit", n)
+		}
+	}
+}
+
+func TestClient_GetMany_Error(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.GetFailures = 1
+
+	_, err := client.GetMany(context.Background(), oc.GetRequest{
+		UUIDs: []string{"a", "b", "c"},
+	}, &oc.GetManyOptions{MaxParamLength: 1})
+	if err == nil {
+		t.Fatal("expected a failed request to fail GetMany")
+	}
+}
@@ -0,0 +1,87 @@
+package oc
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// HighlightOptions controls the highlighted fragments of a search.
+type HighlightOptions struct {
+	// FragmentSize is the approximate size of a fragment in
+	// characters.
+	FragmentSize int
+	// Snippets is the maximum number of fragments per field.
+	Snippets int
+	// PreTag and PostTag surround the highlighted terms, OC uses
+	// <em> and </em> by default.
+	PreTag  string
+	PostTag string
+}
+
+func (ho HighlightOptions) addQueryValues(q url.Values) {
+	if ho.FragmentSize > 0 {
+		q.Set("highlight.fragsize", strconv.Itoa(ho.FragmentSize))
+	}
+
+	if ho.Snippets > 0 {
+		q.Set("highlight.snippets", strconv.Itoa(ho.Snippets))
+	}
+
+	if ho.PreTag != "" {
+		q.Set("highlight.pre", ho.PreTag)
+	}
+
+	if ho.PostTag != "" {
+		q.Set("highlight.post", ho.PostTag)
+	}
+}
+
+// Highlights are highlighted fragments by index field.
+type Highlights map[string][]string
+
+// Get returns the first fragment for a field.
+func (h Highlights) Get(field string) (string, bool) {
+	if len(h[field]) == 0 {
+		return "", false
+	}
+
+	return h[field][0], true
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the highlights are
+// joined onto the hits.
+func (sr *SearchResponse) UnmarshalJSON(data []byte) error {
+	type plain SearchResponse
+
+	var p plain
+
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for i := range p.Hits.Items {
+		hl, ok := p.Highlight[p.Hits.Items[i].ID]
+		if !ok || len(hl) == 0 {
+			continue
+		}
+
+		p.Hits.Items[i].Highlights = highlightsFromProperties(hl)
+	}
+
+	*sr = SearchResponse(p)
+
+	return nil
+}
+
+func highlightsFromProperties(p Properties) Highlights {
+	h := make(Highlights, len(p))
+
+	for field := range p {
+		values, _ := p.GetValues(field)
+
+		h[field] = values
+	}
+
+	return h
+}
@@ -0,0 +1,130 @@
+package oc_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestSearchResponse_Highlights(t *testing.T) {
+	var resp oc.SearchResponse
+
+	loadTestData(t, "searchresponse.json", &resp)
+
+	var found bool
+
+	for _, hit := range resp.Hits.Items {
+		if hit.ID != "4e6be524-c3ea-431d-9621-253d4d936d5c" {
+			continue
+		}
+
+		found = true
+
+		headline, ok := hit.Highlights.Get("Headline")
+		if !ok || headline != "Electric <em>scooters</em> cause chaos and mayhem" {
+			t.Errorf("unexpected headline highlight %q", headline)
+		}
+	}
+
+	if !found {
+		t.Fatal("expected to find the highlighted hit")
+	}
+
+	if len(resp.Highlight) == 0 {
+		t.Error("expected the raw highlights to be kept")
+	}
+
+	if resp.Stats.Elapsed() != 49*time.Millisecond {
+		t.Errorf("unexpected search duration %v", resp.Stats.Elapsed())
+	}
+}
+
+func TestSearchRequest_HighlightOptions(t *testing.T) {
+	req := oc.SearchRequest{
+		Highlight: []string{"Headline", "ArticleBody"},
+		HighlightOptions: oc.HighlightOptions{
+			FragmentSize: 120,
+			Snippets:     3,
+			PreTag:       "<mark>",
+			PostTag:      "</mark>",
+		},
+	}
+
+	q, err := req.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := url.Values{
+		"highlight.indexfield": {"Headline\nArticleBody"},
+		"highlight.fragsize":   {"120"},
+		"highlight.snippets":   {"3"},
+		"highlight.pre":        {"<mark>"},
+		"highlight.post":       {"</mark>"},
+		"start":                {"0"},
+		"limit":                {"15"},
+	}
+
+	if diff := cmp.Diff(want, q); diff != "" {
+		t.Errorf("QueryValues() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStats_Unmarshal(t *testing.T) {
+	data := []byte(`{
+  "duration": 12,
+  "hits": {
+    "ArticleMetaNewsValue": {"min": 1, "max": 6, "count": 40, "missing": 2, "sum": 120, "mean": 3, "stddev": 1.5},
+    "created": {"min": "2020-01-01T00:00:00Z", "max": "2021-01-01T00:00:00Z", "count": 42}
+  }
+}`)
+
+	var stats oc.Stats
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatalf("failed to unmarshal stats: %v", err)
+	}
+
+	want := oc.Stats{
+		Duration: 12,
+		Hits: map[string]oc.FieldStats{
+			"ArticleMetaNewsValue": {
+				Min: "1", Max: "6", Count: 40, Missing: 2, Sum: 120, Mean: 3, StdDev: 1.5,
+			},
+			"created": {
+				Min: "2020-01-01T00:00:00Z", Max: "2021-01-01T00:00:00Z", Count: 42,
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, stats); diff != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", diff)
+	}
+
+	roundTrip, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var again oc.Stats
+
+	if err := json.Unmarshal(roundTrip, &again); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(stats, again); diff != "" {
+		t.Errorf("stats changed in a round trip (-want +got):\n%s", diff)
+	}
+
+	if err := json.Unmarshal([]byte(`{"duration": 5, "hits": 17}`), &stats); err != nil {
+		t.Errorf("expected unknown hit stats to be ignored, got: %v", err)
+	}
+
+	if stats.Duration != 5 || stats.Hits != nil {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,113 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// SourceNamespace returns the UUID namespace for keys from a source
+// system. The namespace is itself a name based UUID, so the same
+// source name always results in the same namespace.
+func SourceNamespace(source string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("oc-source:"+source))
+}
+
+// ObjectUUID derives a name based (version 5) UUID for an object from
+// its key in a source system. The same namespace and key always gives
+// the same UUID, so uploads can be safely repeated.
+func ObjectUUID(namespace uuid.UUID, key string) string {
+	return uuid.NewSHA1(namespace, []byte(key)).String()
+}
+
+// objectUUID returns the UUID that the request should be uploaded as,
+// derived from the UUIDKey if no UUID has been given.
+func (req UploadRequest) objectUUID() string {
+	if req.UUID != "" || req.UUIDKey == "" {
+		return req.UUID
+	}
+
+	namespace := req.UUIDNamespace
+	if namespace == uuid.Nil {
+		namespace = SourceNamespace(req.Source)
+	}
+
+	return ObjectUUID(namespace, req.UUIDKey)
+}
+
+// ExistingPolicy decides what UploadIfAbsent does when the object
+// already exists.
+type ExistingPolicy int
+
+const (
+	// ExistingSkip leaves the existing object as it is.
+	ExistingSkip ExistingPolicy = iota
+	// ExistingUpdate uploads a new version of the existing object,
+	// conditional on it not having changed since it was checked.
+	ExistingUpdate
+)
+
+func (ep ExistingPolicy) String() string {
+	switch ep {
+	case ExistingSkip:
+		return "skip"
+	case ExistingUpdate:
+		return "update"
+	default:
+		return "unknown"
+	}
+}
+
+// UploadIfAbsentResponse is the result of UploadIfAbsent.
+type UploadIfAbsentResponse struct {
+	UploadResponse
+
+	// Skipped is true if the object already existed and no upload
+	// was made. The response then describes the existing object.
+	Skipped bool
+}
+
+// UploadIfAbsent checks if the object exists before uploading it,
+// and either skips the upload or updates the object according to the
+// policy. The request must have a UUID or UUIDKey, which makes
+// re-running an ingest job idempotent.
+func (c *Client) UploadIfAbsent(
+	ctx context.Context, req UploadRequest, existing ExistingPolicy,
+) (*UploadIfAbsentResponse, error) {
+	req.UUID = req.objectUUID()
+
+	if req.UUID == "" {
+		return nil, errors.New("a UUID or UUIDKey is required to check if the object exists")
+	}
+
+	current, err := c.CheckExists(ctx, req.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if %s exists: %w", req.UUID, err)
+	}
+
+	if current.Exists {
+		if existing == ExistingSkip {
+			return &UploadIfAbsentResponse{
+				UploadResponse: UploadResponse{
+					UUID:    req.UUID,
+					ETag:    current.ETag,
+					Version: current.Version,
+				},
+				Skipped: true,
+			}, nil
+		}
+
+		if req.IfMatch == "" {
+			req.IfMatch = current.ETag
+		}
+	}
+
+	res, err := c.Upload(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadIfAbsentResponse{UploadResponse: *res}, nil
+}
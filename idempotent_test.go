@@ -0,0 +1,84 @@
+package oc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestObjectUUID(t *testing.T) {
+	ns := oc.SourceNamespace("photo-archive")
+
+	a := oc.ObjectUUID(ns, "IMG-0001")
+	b := oc.ObjectUUID(ns, "IMG-0001")
+
+	if a != b {
+		t.Errorf("expected the same key to give the same UUID, got %s and %s", a, b)
+	}
+
+	if a == oc.ObjectUUID(oc.SourceNamespace("wire"), "IMG-0001") {
+		t.Error("expected different namespaces to give different UUIDs")
+	}
+
+	parsed, err := uuid.Parse(a)
+	if err != nil {
+		t.Fatalf("invalid UUID %q: %v", a, err)
+	}
+
+	if parsed.Version() != 5 {
+		t.Errorf("expected a version 5 UUID, got version %d", parsed.Version())
+	}
+}
+
+func TestClient_UploadIfAbsent(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	request := func(content string) oc.UploadRequest {
+		return oc.UploadRequest{
+			Source:  "photo-archive",
+			UUIDKey: "IMG-0001",
+			Files: oc.FileSet{
+				"file": {
+					Name:     "sample.jpeg",
+					Reader:   strings.NewReader(content),
+					Mimetype: "image/jpeg",
+				},
+			},
+		}
+	}
+
+	ctx := context.Background()
+
+	first, err := client.UploadIfAbsent(ctx, request("v1"), oc.ExistingSkip)
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	want := oc.ObjectUUID(oc.SourceNamespace("photo-archive"), "IMG-0001")
+
+	if first.Skipped || first.UUID != want {
+		t.Fatalf("expected %s to be uploaded, got %+v", want, first)
+	}
+
+	second, err := client.UploadIfAbsent(ctx, request("v2"), oc.ExistingSkip)
+	if err != nil {
+		t.Fatalf("failed to re-run upload: %v", err)
+	}
+
+	if !second.Skipped || second.ETag != first.ETag || fake.Uploads != 1 {
+		t.Errorf("expected the re-run to be skipped, got %+v after %d uploads",
+			second, fake.Uploads)
+	}
+
+	third, err := client.UploadIfAbsent(ctx, request("v3"), oc.ExistingUpdate)
+	if err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	if third.Skipped || third.Version != 2 {
+		t.Errorf("expected a second version to be uploaded, got %+v", third)
+	}
+}
@@ -0,0 +1,273 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	jpegSignature = []byte{0xFF, 0xD8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+
+	exifHeader      = []byte("Exif\x00\x00")
+	xmpHeader       = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	photoshopHeader = []byte("Photoshop 3.0\x00")
+	pngXMPKeyword   = []byte("XML:com.adobe.xmp")
+)
+
+var errTruncated = errors.New("truncated data")
+
+// jpegBlocks collects the metadata from the APP1 and APP13 segments
+// that precede the image data.
+func jpegBlocks(data []byte) (metadataBlocks, error) {
+	var blocks metadataBlocks
+
+	pos := len(jpegSignature)
+
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return blocks, fmt.Errorf("expected a marker at offset %d", pos)
+		}
+
+		marker := data[pos+1]
+
+		// Padding and markers without a payload.
+		if marker == 0xFF {
+			pos++
+
+			continue
+		}
+
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			pos += 2
+
+			continue
+		}
+
+		// Start of scan or end of image, no more metadata.
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return blocks, errTruncated
+		}
+
+		payload := data[pos+4 : pos+2+length]
+
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader):
+			blocks.exif = payload[len(exifHeader):]
+		case marker == 0xE1 && bytes.HasPrefix(payload, xmpHeader):
+			blocks.xmp = payload[len(xmpHeader):]
+		case marker == 0xED && bytes.HasPrefix(payload, photoshopHeader):
+			blocks.iptc = photoshopIPTC(payload[len(photoshopHeader):])
+		}
+
+		pos += 2 + length
+	}
+
+	return blocks, nil
+}
+
+// photoshopIPTC extracts the IPTC-IIM resource from Photoshop image
+// resource blocks.
+func photoshopIPTC(data []byte) []byte {
+	const iptcResource = 0x0404
+
+	for len(data) >= 12 && bytes.HasPrefix(data, []byte("8BIM")) {
+		id := binary.BigEndian.Uint16(data[4:])
+
+		// Pascal string name, padded to an even length.
+		nameLen := int(data[6]) + 1
+		if nameLen%2 != 0 {
+			nameLen++
+		}
+
+		sizeAt := 6 + nameLen
+		if sizeAt+4 > len(data) {
+			return nil
+		}
+
+		size := int(binary.BigEndian.Uint32(data[sizeAt:]))
+		start := sizeAt + 4
+
+		if size < 0 || start+size > len(data) {
+			return nil
+		}
+
+		if id == iptcResource {
+			return data[start : start+size]
+		}
+
+		next := start + size
+		if size%2 != 0 {
+			next++
+		}
+
+		if next > len(data) {
+			return nil
+		}
+
+		data = data[next:]
+	}
+
+	return nil
+}
+
+// pngBlocks collects the metadata from eXIf and XMP iTXt chunks.
+func pngBlocks(data []byte) (metadataBlocks, error) {
+	var blocks metadataBlocks
+
+	pos := len(pngSignature)
+
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+
+		end := pos + 8 + length + 4 // Data followed by the CRC.
+		if length < 0 || end > len(data) {
+			return blocks, errTruncated
+		}
+
+		chunk := data[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "eXIf":
+			blocks.exif = chunk
+		case "iTXt":
+			xmp, err := pngXMP(chunk)
+			if err != nil {
+				return blocks, err
+			}
+
+			if xmp != nil {
+				blocks.xmp = xmp
+			}
+		case "IEND":
+			return blocks, nil
+		}
+
+		pos = end
+	}
+
+	return blocks, nil
+}
+
+// pngXMP returns the text of an iTXt chunk if it holds XMP.
+func pngXMP(chunk []byte) ([]byte, error) {
+	keyword, rest, ok := bytes.Cut(chunk, []byte{0})
+	if !ok || !bytes.Equal(keyword, pngXMPKeyword) || len(rest) < 2 {
+		return nil, nil
+	}
+
+	compressed := rest[0] == 1
+
+	// Skip the compression flag and method, then the language tag
+	// and translated keyword.
+	rest = rest[2:]
+
+	for i := 0; i < 2; i++ {
+		_, rest, ok = bytes.Cut(rest, []byte{0})
+		if !ok {
+			return nil, errTruncated
+		}
+	}
+
+	if !compressed {
+		return rest, nil
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(rest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress XMP: %w", err)
+	}
+
+	text, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress XMP: %w", err)
+	}
+
+	return text, nil
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 &&
+		bytes.Equal(data[0:4], []byte("RIFF")) &&
+		bytes.Equal(data[8:12], []byte("WEBP"))
+}
+
+// webpBlocks reads the canvas size and metadata chunks of a WebP
+// image. The standard library has no WebP decoder, so the size is
+// read from the VP8, VP8L or VP8X chunk.
+func webpBlocks(data []byte) (metadataBlocks, int, int, error) {
+	var (
+		blocks        metadataBlocks
+		width, height int
+	)
+
+	pos := 12
+
+	for pos+8 <= len(data) {
+		chunkType := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+
+		if length < 0 || pos+8+length > len(data) {
+			return blocks, 0, 0, errTruncated
+		}
+
+		chunk := data[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "VP8X":
+			if len(chunk) < 10 {
+				return blocks, 0, 0, errTruncated
+			}
+
+			width = int(uint24(chunk[4:])) + 1
+			height = int(uint24(chunk[7:])) + 1
+		case "VP8 ":
+			if len(chunk) < 10 || !bytes.Equal(chunk[3:6], []byte{0x9D, 0x01, 0x2A}) {
+				return blocks, 0, 0, errors.New("invalid VP8 frame header")
+			}
+
+			if width == 0 {
+				width = int(binary.LittleEndian.Uint16(chunk[6:]) & 0x3FFF)
+				height = int(binary.LittleEndian.Uint16(chunk[8:]) & 0x3FFF)
+			}
+		case "VP8L":
+			if len(chunk) < 5 || chunk[0] != 0x2F {
+				return blocks, 0, 0, errors.New("invalid VP8L header")
+			}
+
+			if width == 0 {
+				bits := binary.LittleEndian.Uint32(chunk[1:])
+
+				width = int(bits&0x3FFF) + 1
+				height = int((bits>>14)&0x3FFF) + 1
+			}
+		case "EXIF":
+			blocks.exif = bytes.TrimPrefix(chunk, exifHeader)
+		case "XMP ":
+			blocks.xmp = chunk
+		}
+
+		// Chunks are padded to an even size.
+		pos += 8 + length + length%2
+	}
+
+	if width == 0 || height == 0 {
+		return blocks, 0, 0, errors.New("no image size found")
+	}
+
+	return blocks, width, height, nil
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
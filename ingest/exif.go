@@ -0,0 +1,158 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EXIF tags that are read.
+const (
+	exifImageDescription = 0x010E
+	exifDateTime         = 0x0132
+	exifArtist           = 0x013B
+	exifCopyright        = 0x8298
+	exifIFDPointer       = 0x8769
+	exifDateTimeOriginal = 0x9003
+)
+
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// parseEXIF reads the descriptive fields from a TIFF structured EXIF
+// block. Tags that are missing or of an unexpected type result in
+// empty fields, but a block that is truncated or has offsets that
+// point outside of it results in an error.
+func parseEXIF(data []byte) (imageFields, error) {
+	var f imageFields
+
+	if len(data) < 8 {
+		return f, errors.New("truncated EXIF header")
+	}
+
+	var order binary.ByteOrder
+
+	switch {
+	case bytes.HasPrefix(data, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return f, errors.New("invalid EXIF byte order")
+	}
+
+	ifd0, err := readIFD(data, order, order.Uint32(data[4:]))
+	if err != nil {
+		return f, fmt.Errorf("invalid IFD0: %w", err)
+	}
+
+	f.caption = ifd0.str(exifImageDescription)
+	f.byline = ifd0.str(exifArtist)
+	f.credit = ifd0.str(exifCopyright)
+
+	created := ifd0.str(exifDateTime)
+
+	if ptr, ok := ifd0.long(exifIFDPointer); ok {
+		exif, err := readIFD(data, order, ptr)
+		if err != nil {
+			return f, fmt.Errorf("invalid EXIF IFD: %w", err)
+		}
+
+		if original := exif.str(exifDateTimeOriginal); original != "" {
+			created = original
+		}
+	}
+
+	if t, err := time.Parse(exifTimeLayout, created); err == nil {
+		f.created = t
+	}
+
+	return f, nil
+}
+
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+type ifd struct {
+	order   binary.ByteOrder
+	entries map[uint16]ifdEntry
+}
+
+func readIFD(data []byte, order binary.ByteOrder, offset uint32) (ifd, error) {
+	res := ifd{
+		order:   order,
+		entries: make(map[uint16]ifdEntry),
+	}
+
+	if uint64(offset)+2 > uint64(len(data)) {
+		return res, fmt.Errorf("offset %d is outside of the data", offset)
+	}
+
+	count := uint64(order.Uint16(data[offset:]))
+	pos := uint64(offset) + 2
+
+	if pos+count*12 > uint64(len(data)) {
+		return res, fmt.Errorf("%d entries at %d don't fit in the data", count, offset)
+	}
+
+	for i := uint64(0); i < count; i++ {
+		entry := data[pos : pos+12]
+		pos += 12
+
+		tag := order.Uint16(entry)
+		typ := order.Uint16(entry[2:])
+		n := order.Uint32(entry[4:])
+
+		var size uint64
+
+		switch typ {
+		case 1, 2, 7: // BYTE, ASCII, UNDEFINED
+			size = uint64(n)
+		case 4: // LONG
+			size = uint64(n) * 4
+		default:
+			continue
+		}
+
+		value := entry[8:min(8+size, 12)]
+
+		if size > 4 {
+			valueOffset := uint64(order.Uint32(entry[8:]))
+			if valueOffset+size > uint64(len(data)) {
+				return res, fmt.Errorf("value of tag %#04x at %d is outside of the data",
+					tag, valueOffset)
+			}
+
+			value = data[valueOffset : valueOffset+size]
+		}
+
+		res.entries[tag] = ifdEntry{typ: typ, count: n, value: value}
+	}
+
+	return res, nil
+}
+
+func (d ifd) str(tag uint16) string {
+	e, ok := d.entries[tag]
+	if !ok || e.typ != 2 {
+		return ""
+	}
+
+	value, _, _ := bytes.Cut(e.value, []byte{0})
+
+	return strings.TrimSpace(toUTF8(value))
+}
+
+func (d ifd) long(tag uint16) (uint32, bool) {
+	e, ok := d.entries[tag]
+	if !ok || e.typ != 4 || len(e.value) < 4 {
+		return 0, false
+	}
+
+	return d.order.Uint32(e.value), true
+}
@@ -0,0 +1,225 @@
+// Package ingest prepares files for upload to Open Content.
+package ingest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Registers the JPEG decoder for image.DecodeConfig.
+	_ "image/png"  // Registers the PNG decoder for image.DecodeConfig.
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+	"github.com/navigacontentlab/oc-client-go/v2/newsml"
+)
+
+// ErrUnsupportedImage is returned for images that aren't JPEG, PNG or
+// WebP.
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// ImageInfo is the information read from an image file.
+type ImageInfo struct {
+	Mimetype string
+	Width    int
+	Height   int
+	Caption  string
+	Byline   string
+	Credit   string
+	// Created is when the picture was taken, zero if unknown.
+	Created  time.Time
+	Keywords []string
+}
+
+// ReadImageInfo reads the dimensions of a JPEG, PNG or WebP image
+// together with the descriptive metadata from its embedded XMP, IPTC
+// and EXIF blocks. XMP values take precedence over IPTC values, which
+// take precedence over EXIF values. An EXIF block that is truncated or
+// has offsets outside of the block results in an error.
+func ReadImageInfo(data []byte) (*ImageInfo, error) {
+	var (
+		info   ImageInfo
+		blocks metadataBlocks
+		err    error
+	)
+
+	switch {
+	case bytes.HasPrefix(data, jpegSignature):
+		info.Mimetype = "image/jpeg"
+		blocks, err = jpegBlocks(data)
+	case bytes.HasPrefix(data, pngSignature):
+		info.Mimetype = "image/png"
+		blocks, err = pngBlocks(data)
+	case isWebP(data):
+		info.Mimetype = "image/webp"
+		blocks, info.Width, info.Height, err = webpBlocks(data)
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", info.Mimetype, err)
+	}
+
+	if info.Mimetype != "image/webp" {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image dimensions: %w", err)
+		}
+
+		info.Width, info.Height = cfg.Width, cfg.Height
+	}
+
+	// Apply the sources in reverse order of precedence.
+	if blocks.exif != nil {
+		fields, err := parseEXIF(blocks.exif)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read EXIF metadata: %w", err)
+		}
+
+		applyFields(&info, fields)
+	}
+
+	if blocks.iptc != nil {
+		applyFields(&info, parseIPTC(blocks.iptc))
+	}
+
+	if blocks.xmp != nil {
+		applyFields(&info, parseXMP(blocks.xmp))
+	}
+
+	return &info, nil
+}
+
+// metadataBlocks are the raw metadata blocks found in an image.
+type metadataBlocks struct {
+	exif []byte
+	iptc []byte
+	xmp  []byte
+}
+
+// imageFields are the descriptive fields read from a metadata block.
+type imageFields struct {
+	caption  string
+	byline   string
+	credit   string
+	created  time.Time
+	keywords []string
+}
+
+func applyFields(info *ImageInfo, f imageFields) {
+	if f.caption != "" {
+		info.Caption = f.caption
+	}
+
+	if f.byline != "" {
+		info.Byline = f.byline
+	}
+
+	if f.credit != "" {
+		info.Credit = f.credit
+	}
+
+	if !f.created.IsZero() {
+		info.Created = f.created
+	}
+
+	if len(f.keywords) > 0 {
+		info.Keywords = f.keywords
+	}
+}
+
+// ImageOptions controls how an image upload is created.
+type ImageOptions struct {
+	// FileName is the name of the image file in OC.
+	FileName string
+	// UUID of the object, derived from UUIDKey or generated if
+	// empty.
+	UUID string
+	// UUIDKey is the key of the image in the source system, see
+	// oc.ObjectUUID.
+	UUIDKey string
+	Source  string
+	Unit    string
+	Batch   bool
+}
+
+// NewImageUpload reads the information in an image and creates an
+// upload request with the image as the primary file and generated
+// NewsML picture metadata.
+func NewImageUpload(data []byte, opts ImageOptions) (*oc.UploadRequest, *ImageInfo, error) {
+	if opts.FileName == "" {
+		return nil, nil, errors.New("a file name is required")
+	}
+
+	info, err := ReadImageInfo(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := opts.UUID
+
+	switch {
+	case id != "":
+	case opts.UUIDKey != "":
+		id = oc.ObjectUUID(oc.SourceNamespace(opts.Source), opts.UUIDKey)
+	default:
+		id = uuid.NewString()
+	}
+
+	item := newsml.NewImage(newsml.Image{
+		GUID:     id,
+		FileName: opts.FileName,
+		Mimetype: info.Mimetype,
+		Width:    info.Width,
+		Height:   info.Height,
+		Caption:  info.Caption,
+		Byline:   info.Byline,
+		Credit:   info.Credit,
+		Created:  info.Created,
+		Keywords: info.Keywords,
+	})
+
+	stem := strings.TrimSuffix(opts.FileName, filepath.Ext(opts.FileName))
+
+	metadata, err := item.File(stem + ".metadata.xml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create metadata: %w", err)
+	}
+
+	req := oc.UploadRequest{
+		UUID:   id,
+		Source: opts.Source,
+		Unit:   opts.Unit,
+		Batch:  opts.Batch,
+		Files: oc.FileSet{
+			"file": {
+				Name:     opts.FileName,
+				Reader:   bytes.NewReader(data),
+				Mimetype: info.Mimetype,
+			},
+			"metadata": metadata,
+		},
+	}
+
+	return &req, info, nil
+}
+
+// NewImageUploadFromFile is NewImageUpload for an image on disk. The
+// file name defaults to the base name of the path.
+func NewImageUploadFromFile(path string, opts ImageOptions) (*oc.UploadRequest, *ImageInfo, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	if opts.FileName == "" {
+		opts.FileName = filepath.Base(path)
+	}
+
+	return NewImageUpload(data, opts)
+}
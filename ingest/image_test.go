@@ -0,0 +1,374 @@
+package ingest_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+	"github.com/navigacontentlab/oc-client-go/v2/ingest"
+	"github.com/navigacontentlab/oc-client-go/v2/newsml"
+)
+
+// testEXIF builds a little endian TIFF block with a description,
+// artist and original date.
+func testEXIF(description, artist, original string) []byte {
+	le := binary.LittleEndian
+
+	var buf bytes.Buffer
+
+	buf.WriteString("II*\x00")
+	_ = binary.Write(&buf, le, uint32(8))
+
+	// IFD0 at 8 with three entries, followed by the EXIF IFD with
+	// one entry and then the string values.
+	ifd0Size := 2 + 3*12 + 4
+	exifIFDAt := 8 + ifd0Size
+	exifIFDSize := 2 + 12 + 4
+	valuesAt := exifIFDAt + exifIFDSize
+
+	values := []string{description + "\x00", artist + "\x00", original + "\x00"}
+
+	offsets := make([]int, len(values))
+	at := valuesAt
+
+	for i, v := range values {
+		offsets[i] = at
+		at += len(v)
+	}
+
+	entry := func(tag, typ uint16, count, value uint32) {
+		_ = binary.Write(&buf, le, tag)
+		_ = binary.Write(&buf, le, typ)
+		_ = binary.Write(&buf, le, count)
+		_ = binary.Write(&buf, le, value)
+	}
+
+	_ = binary.Write(&buf, le, uint16(3))
+	entry(0x010E, 2, uint32(len(values[0])), uint32(offsets[0]))
+	entry(0x013B, 2, uint32(len(values[1])), uint32(offsets[1]))
+	entry(0x8769, 4, 1, uint32(exifIFDAt))
+	_ = binary.Write(&buf, le, uint32(0))
+
+	_ = binary.Write(&buf, le, uint16(1))
+	entry(0x9003, 2, uint32(len(values[2])), uint32(offsets[2]))
+	_ = binary.Write(&buf, le, uint32(0))
+
+	for _, v := range values {
+		buf.WriteString(v)
+	}
+
+	return buf.Bytes()
+}
+
+func testIPTC(datasets map[byte][]string) []byte {
+	var iim bytes.Buffer
+
+	for _, ds := range []byte{5, 25, 55, 60, 80, 110, 120} {
+		for _, v := range datasets[ds] {
+			iim.Write([]byte{0x1C, 2, ds})
+			_ = binary.Write(&iim, binary.BigEndian, uint16(len(v)))
+			iim.WriteString(v)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("Photoshop 3.0\x00")
+
+	// An unrelated resource before the IPTC one.
+	buf.WriteString("8BIM")
+	_ = binary.Write(&buf, binary.BigEndian, uint16(0x03ED))
+	buf.Write([]byte{0, 0})
+	_ = binary.Write(&buf, binary.BigEndian, uint32(3))
+	buf.Write([]byte{1, 2, 3, 0})
+
+	buf.WriteString("8BIM")
+	_ = binary.Write(&buf, binary.BigEndian, uint16(0x0404))
+	buf.Write([]byte{0, 0})
+	_ = binary.Write(&buf, binary.BigEndian, uint32(iim.Len()))
+	buf.Write(iim.Bytes())
+
+	return buf.Bytes()
+}
+
+const testXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/"
+    photoshop:DateCreated="2021-05-04T10:20:30+02:00">
+   <dc:description><rdf:Alt><rdf:li xml:lang="x-default">XMP caption</rdf:li></rdf:Alt></dc:description>
+   <dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li></rdf:Seq></dc:creator>
+   <dc:subject><rdf:Bag><rdf:li>harbour</rdf:li><rdf:li>boats</rdf:li></rdf:Bag></dc:subject>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+
+func testJPEG(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 32, 24)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := buf.Bytes()
+
+	var out bytes.Buffer
+
+	out.Write(encoded[:2])
+
+	for _, s := range segments {
+		out.Write(s)
+	}
+
+	out.Write(encoded[2:])
+
+	return out.Bytes()
+}
+
+func segment(marker byte, header string, payload []byte) []byte {
+	var buf bytes.Buffer
+
+	buf.Write([]byte{0xFF, marker})
+	_ = binary.Write(&buf, binary.BigEndian, uint16(2+len(header)+len(payload)))
+	buf.WriteString(header)
+	buf.Write(payload)
+
+	return buf.Bytes()
+}
+
+func TestReadImageInfo__JPEG(t *testing.T) {
+	exif := segment(0xE1, "Exif\x00\x00",
+		testEXIF("EXIF caption", "Exif Artist", "2020:01:02 03:04:05"))
+
+	data := testJPEG(t, exif)
+
+	info, err := ingest.ReadImageInfo(data)
+	if err != nil {
+		t.Fatalf("failed to read image info: %v", err)
+	}
+
+	want := ingest.ImageInfo{
+		Mimetype: "image/jpeg",
+		Width:    32,
+		Height:   24,
+		Caption:  "EXIF caption",
+		Byline:   "Exif Artist",
+		Created:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	if diff := cmp.Diff(want, *info); diff != "" {
+		t.Errorf("image info mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadImageInfo__InvalidEXIF(t *testing.T) {
+	valid := testEXIF("EXIF caption", "Exif Artist", "2020:01:02 03:04:05")
+
+	// IFD0 with a string value that points past the end of the data.
+	outside := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint32(outside[8+2+8:], 0xFFFFFFF0)
+
+	for name, payload := range map[string][]byte{
+		"header only":    []byte("II*\x00"),
+		"empty":          nil,
+		"byte order":     []byte("XX*\x00\x08\x00\x00\x00"),
+		"ifd0 offset":    []byte("II*\x00\xFF\xFF\xFF\xFF"),
+		"truncated ifd0": valid[:20],
+		"value offset":   outside,
+	} {
+		data := testJPEG(t, segment(0xE1, "Exif\x00\x00", payload))
+
+		_, err := ingest.ReadImageInfo(data)
+		if err == nil {
+			t.Errorf("%s: expected an error for invalid EXIF data", name)
+		}
+	}
+}
+
+func TestReadImageInfo__Precedence(t *testing.T) {
+	data := testJPEG(t,
+		segment(0xE1, "Exif\x00\x00",
+			testEXIF("EXIF caption", "Exif Artist", "2020:01:02 03:04:05")),
+		segment(0xED, "", testIPTC(map[byte][]string{
+			25:  {"iptc keyword"},
+			55:  {"20190807"},
+			80:  {"IPTC Byline"},
+			110: {"IPTC Credit"},
+			120: {"IPTC caption"},
+		})),
+		segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00", []byte(testXMP)),
+	)
+
+	info, err := ingest.ReadImageInfo(data)
+	if err != nil {
+		t.Fatalf("failed to read image info: %v", err)
+	}
+
+	want := ingest.ImageInfo{
+		Mimetype: "image/jpeg",
+		Width:    32,
+		Height:   24,
+		Caption:  "XMP caption",
+		Byline:   "Jane Doe",
+		Credit:   "IPTC Credit",
+		Created:  time.Date(2021, 5, 4, 8, 20, 30, 0, time.UTC),
+		Keywords: []string{"harbour", "boats"},
+	}
+
+	if diff := cmp.Diff(want, *info, cmp.Comparer(time.Time.Equal)); diff != "" {
+		t.Errorf("image info mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	var buf bytes.Buffer
+
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.WriteString(typ)
+	buf.Write(data)
+
+	crc := crc32.NewIEEE()
+
+	_, _ = io.WriteString(crc, typ)
+	_, _ = crc.Write(data)
+
+	_ = binary.Write(&buf, binary.BigEndian, crc.Sum32())
+
+	return buf.Bytes()
+}
+
+func TestReadImageInfo__PNG(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 10, 20)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := buf.Bytes()
+
+	// Insert the XMP chunk after the signature and IHDR chunk.
+	ihdrEnd := 8 + 8 + 13 + 4
+
+	var data bytes.Buffer
+
+	data.Write(encoded[:ihdrEnd])
+	data.Write(pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+testXMP)))
+	data.Write(encoded[ihdrEnd:])
+
+	info, err := ingest.ReadImageInfo(data.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read image info: %v", err)
+	}
+
+	if info.Mimetype != "image/png" || info.Width != 10 || info.Height != 20 {
+		t.Errorf("unexpected image %s %dx%d", info.Mimetype, info.Width, info.Height)
+	}
+
+	if info.Caption != "XMP caption" {
+		t.Errorf("unexpected caption %q", info.Caption)
+	}
+}
+
+func TestReadImageInfo__WebP(t *testing.T) {
+	riffChunk := func(typ string, data []byte) []byte {
+		var buf bytes.Buffer
+
+		buf.WriteString(typ)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+		buf.Write(data)
+
+		if len(data)%2 != 0 {
+			buf.WriteByte(0)
+		}
+
+		return buf.Bytes()
+	}
+
+	// Canvas of 800x600, stored as width-1 and height-1.
+	vp8x := []byte{0x04, 0, 0, 0, 0x1F, 0x03, 0, 0x57, 0x02, 0}
+
+	var body bytes.Buffer
+
+	body.WriteString("WEBP")
+	body.Write(riffChunk("VP8X", vp8x))
+	body.Write(riffChunk("XMP ", []byte(testXMP)))
+
+	var data bytes.Buffer
+
+	data.WriteString("RIFF")
+	_ = binary.Write(&data, binary.LittleEndian, uint32(body.Len()))
+	data.Write(body.Bytes())
+
+	info, err := ingest.ReadImageInfo(data.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read image info: %v", err)
+	}
+
+	if info.Mimetype != "image/webp" || info.Width != 800 || info.Height != 600 {
+		t.Errorf("unexpected image %s %dx%d", info.Mimetype, info.Width, info.Height)
+	}
+
+	if info.Byline != "Jane Doe" {
+		t.Errorf("unexpected byline %q", info.Byline)
+	}
+}
+
+func TestNewImageUpload(t *testing.T) {
+	data := testJPEG(t, segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00", []byte(testXMP)))
+
+	req, info, err := ingest.NewImageUpload(data, ingest.ImageOptions{
+		FileName: "harbour.jpg",
+		UUIDKey:  "IMG-42",
+		Source:   "archive",
+	})
+	if err != nil {
+		t.Fatalf("failed to create upload: %v", err)
+	}
+
+	if want := oc.ObjectUUID(oc.SourceNamespace("archive"), "IMG-42"); req.UUID != want {
+		t.Errorf("expected UUID %s, got %s", want, req.UUID)
+	}
+
+	if f := req.Files["file"]; f.Name != "harbour.jpg" || f.Mimetype != info.Mimetype {
+		t.Errorf("unexpected primary file %+v", f)
+	}
+
+	metadata := req.Files["metadata"]
+
+	if metadata.Name != "harbour.metadata.xml" || metadata.Mimetype != newsml.MimetypePicture {
+		t.Errorf("unexpected metadata file %q %q", metadata.Name, metadata.Mimetype)
+	}
+
+	xmlData, err := io.ReadAll(metadata.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := newsml.Parse(xmlData)
+	if err != nil {
+		t.Fatalf("invalid metadata: %v", err)
+	}
+
+	if item.GUID != req.UUID {
+		t.Errorf("expected the metadata GUID to match the UUID, got %s", item.GUID)
+	}
+
+	object := item.Object("x-im/image")
+
+	if object.Data.Get("width") != "32" || object.Data.Get("text") != "XMP caption" {
+		t.Errorf("unexpected image data in metadata:\n%s", xmlData)
+	}
+}
@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"encoding/binary"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// IPTC-IIM application record datasets that are read.
+const (
+	iptcKeywords    = 25
+	iptcDateCreated = 55
+	iptcTimeCreated = 60
+	iptcByline      = 80
+	iptcCredit      = 110
+	iptcCaption     = 120
+)
+
+// parseIPTC reads the descriptive fields from IPTC-IIM data.
+func parseIPTC(data []byte) imageFields {
+	var (
+		f          imageFields
+		date, tstr string
+	)
+
+	for len(data) >= 5 && data[0] == 0x1C {
+		record, dataset := data[1], data[2]
+		size := int(binary.BigEndian.Uint16(data[3:]))
+
+		// Extended datasets are only used for large binary values.
+		if size&0x8000 != 0 || 5+size > len(data) {
+			break
+		}
+
+		value := strings.TrimSpace(toUTF8(data[5 : 5+size]))
+		data = data[5+size:]
+
+		if record != 2 {
+			continue
+		}
+
+		switch dataset {
+		case iptcKeywords:
+			f.keywords = append(f.keywords, value)
+		case iptcDateCreated:
+			date = value
+		case iptcTimeCreated:
+			tstr = value
+		case iptcByline:
+			if f.byline == "" {
+				f.byline = value
+			}
+		case iptcCredit:
+			f.credit = value
+		case iptcCaption:
+			f.caption = value
+		}
+	}
+
+	f.created = iptcTime(date, tstr)
+
+	return f
+}
+
+// iptcTime combines the CCYYMMDD date and HHMMSS±HHMM time datasets.
+func iptcTime(date, clock string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+
+	if clock != "" {
+		for _, layout := range []string{"20060102150405-0700", "20060102150405"} {
+			if t, err := time.Parse(layout, date+clock); err == nil {
+				return t
+			}
+		}
+	}
+
+	t, err := time.Parse("20060102", date)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// toUTF8 returns the text as is if it's valid UTF-8, and otherwise
+// decodes it as Latin-1, the most common legacy encoding in image
+// metadata.
+func toUTF8(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+
+	runes := make([]rune, len(b))
+
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,129 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// XMP namespaces that are read.
+const (
+	nsRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC        = "http://purl.org/dc/elements/1.1/"
+	nsPhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+)
+
+var (
+	xmpDescription = xml.Name{Space: nsDC, Local: "description"}
+	xmpCreator     = xml.Name{Space: nsDC, Local: "creator"}
+	xmpSubject     = xml.Name{Space: nsDC, Local: "subject"}
+	xmpCredit      = xml.Name{Space: nsPhotoshop, Local: "Credit"}
+	xmpDateCreated = xml.Name{Space: nsPhotoshop, Local: "DateCreated"}
+)
+
+// parseXMP reads the descriptive fields from an XMP packet. Values
+// can be given as attributes of rdf:Description, as simple elements,
+// or as rdf:Alt, rdf:Bag or rdf:Seq lists.
+func parseXMP(data []byte) imageFields {
+	values := make(map[xml.Name][]string)
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		property xml.Name
+		text     strings.Builder
+		inItem   bool
+		hadItems bool
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space == nsRDF && t.Name.Local == "Description" {
+				for _, a := range t.Attr {
+					values[a.Name] = append(values[a.Name], a.Value)
+				}
+
+				continue
+			}
+
+			if t.Name.Space == nsRDF && t.Name.Local == "li" && property.Local != "" {
+				inItem = true
+				hadItems = true
+
+				text.Reset()
+
+				continue
+			}
+
+			if t.Name.Space == nsDC || t.Name.Space == nsPhotoshop {
+				property = t.Name
+				hadItems = false
+
+				text.Reset()
+			}
+		case xml.CharData:
+			if property.Local != "" {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch {
+			case inItem && t.Name.Space == nsRDF && t.Name.Local == "li":
+				values[property] = append(values[property], strings.TrimSpace(text.String()))
+				inItem = false
+			case t.Name == property:
+				if !hadItems {
+					values[property] = append(values[property], strings.TrimSpace(text.String()))
+				}
+
+				property = xml.Name{}
+			}
+		}
+	}
+
+	first := func(name xml.Name) string {
+		for _, v := range values[name] {
+			if v != "" {
+				return v
+			}
+		}
+
+		return ""
+	}
+
+	f := imageFields{
+		caption:  first(xmpDescription),
+		byline:   strings.Join(values[xmpCreator], ", "),
+		credit:   first(xmpCredit),
+		keywords: values[xmpSubject],
+		created:  xmpTime(first(xmpDateCreated)),
+	}
+
+	return f
+}
+
+// xmpTime parses an XMP date, which can leave out the time, seconds
+// or time zone.
+func xmpTime(value string) time.Time {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
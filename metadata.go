@@ -0,0 +1,170 @@
+package oc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"time"
+)
+
+// updateMetadataAttempts is the number of times UpdateMetadata will
+// try to write the metadata file before giving up on concurrent
+// modifications.
+const updateMetadataAttempts = 5
+
+// updateMetadataRetryDelay is the delay before the first retry of
+// UpdateMetadata, it doubles with every retry and is jittered so that
+// competing writers don't retry in lockstep.
+const updateMetadataRetryDelay = 50 * time.Millisecond
+
+// MetadataUpdateFunc receives the current contents of a metadata
+// file and returns the updated contents. It can be called several
+// times if the object is modified concurrently.
+type MetadataUpdateFunc func(current []byte) ([]byte, error)
+
+// UpdateMetadataResponse describes the object after a metadata update.
+type UpdateMetadataResponse struct {
+	ETag    string
+	Version int64
+	// Attempts is the number of read-modify-write cycles that were
+	// performed.
+	Attempts int
+}
+
+// UpdateMetadata performs a read-modify-write cycle on the metadata
+// file of an object. The metadata file is replaced with the result of
+// fn on the condition that the object hasn't been changed since it
+// was read. If it has, the cycle is retried a bounded number of
+// times, with a short backoff between attempts, before an error
+// wrapping ErrConcurrentModification is returned. Nothing is written
+// if fn returns the contents unchanged.
+func (c *Client) UpdateMetadata(
+	ctx context.Context, uuid string, fn MetadataUpdateFunc,
+) (*UpdateMetadataResponse, error) {
+	var lastErr error
+
+	delay := updateMetadataRetryDelay
+
+	for attempt := 1; attempt <= updateMetadataAttempts; attempt++ {
+		if attempt > 1 {
+			jittered := delay/2 + rand.N(delay/2) //nolint:gosec
+
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("metadata update was interrupted: %w: %w",
+					ctx.Err(), lastErr)
+			case <-time.After(jittered):
+			}
+
+			delay *= 2
+		}
+
+		res, err := c.updateMetadata(ctx, uuid, fn)
+		if IsPreconditionFailed(err) {
+			lastErr = err
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.Attempts = attempt
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("failed to update metadata after %d attempts: %w: %w",
+		updateMetadataAttempts, ErrConcurrentModification, lastErr)
+}
+
+func (c *Client) updateMetadata(
+	ctx context.Context, uuid string, fn MetadataUpdateFunc,
+) (*UpdateMetadataResponse, error) {
+	exists, err := c.CheckExists(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current ETag: %w", err)
+	}
+
+	if !exists.Exists {
+		return nil, fmt.Errorf("object %s does not exist", uuid)
+	}
+
+	list, err := c.ListFiles(ctx, uuid, exists.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	if len(list.Metadata) == 0 {
+		return nil, errors.New("the object has no metadata file")
+	}
+
+	meta, err := c.GetMetadataFile(ctx, uuid, int(exists.Version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata file: %w", err)
+	}
+
+	defer safeClose(c.logger, "metadata body", meta.Body)
+
+	current, err := io.ReadAll(meta.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	updated, err := fn(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(current, updated) {
+		return &UpdateMetadataResponse{
+			ETag:    exists.ETag,
+			Version: exists.Version,
+		}, nil
+	}
+
+	contentType := list.Metadata[0].Mimetype
+	if contentType == "" {
+		contentType = meta.ContentType
+	}
+
+	header, err := c.replaceMetadataFile(ctx, ReplaceMetadataRequest{
+		UUID:        uuid,
+		Filename:    list.Metadata[0].Name,
+		ContentType: contentType,
+		Body:        bytes.NewReader(updated),
+		IfMatch:     exists.ETag,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := UpdateMetadataResponse{
+		ETag: header.Get("ETag"),
+	}
+
+	res.Version, err = objectVersionFromHeader(header, versionOptional)
+	if err != nil {
+		c.logger.Logf("failed to get version for metadata update: %v", err)
+	}
+
+	if res.ETag != "" && res.Version != 0 {
+		return &res, nil
+	}
+
+	// Fall back to asking for the current state of the object
+	// when OC doesn't tell us what it ended up as.
+	after, err := c.CheckExists(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated ETag: %w", err)
+	}
+
+	res.ETag = after.ETag
+	res.Version = after.Version
+
+	return &res, nil
+}
@@ -0,0 +1,145 @@
+package oc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestClient_UpdateMetadata(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image", "<newsItem>v1</newsItem>"))
+
+	var calls int
+
+	res, err := client.UpdateMetadata(context.Background(), testImageUUID,
+		func(current []byte) ([]byte, error) {
+			calls++
+
+			// Simulate a concurrent edit the first time around.
+			if calls == 1 {
+				fake.AddVersion(testImageUUID, testImageVersion(
+					"image", "<newsItem>v2</newsItem>"))
+			}
+
+			return bytes.ReplaceAll(current, []byte("</newsItem>"), []byte("+edit</newsItem>")), nil
+		})
+	if err != nil {
+		t.Fatalf("failed to update metadata: %v", err)
+	}
+
+	if calls != 2 || res.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d calls and %d attempts", calls, res.Attempts)
+	}
+
+	current, version := fake.Version(testImageUUID, 0)
+
+	if res.Version != version || res.ETag != current.etag() {
+		t.Errorf("expected version %d and ETag %q, got %d and %q",
+			version, current.etag(), res.Version, res.ETag)
+	}
+
+	got := string(current.Files["sample-image.metadata.xml"].Data)
+	if got != "<newsItem>v2+edit</newsItem>" {
+		t.Errorf("unexpected metadata after update: %q", got)
+	}
+}
+
+func TestClient_UpdateMetadata__GiveUp(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image", "<newsItem/>"))
+
+	var calls int
+
+	_, err := client.UpdateMetadata(context.Background(), testImageUUID,
+		func(current []byte) ([]byte, error) {
+			calls++
+
+			fake.AddVersion(testImageUUID, testImageVersion(
+				"image", fmt.Sprintf("<newsItem>%d</newsItem>", calls)))
+
+			return append(current, '\n'), nil
+		})
+	if !errors.Is(err, oc.ErrConcurrentModification) {
+		t.Fatalf("expected a concurrent modification error, got: %v", err)
+	}
+}
+
+// cancelOnStatus cancels a context when a response with the given
+// status code has been received.
+type cancelOnStatus struct {
+	status int
+	cancel context.CancelFunc
+}
+
+func (c cancelOnStatus) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil && res.StatusCode == c.status {
+		c.cancel()
+	}
+
+	return res, err //nolint:wrapcheck
+}
+
+func TestClient_UpdateMetadata__Cancelled(t *testing.T) {
+	fake, _ := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image", "<newsItem/>"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := oc.New(oc.Options{
+		BaseURL: fake.URL,
+		HTTPClient: &http.Client{
+			Transport: cancelOnStatus{status: http.StatusPreconditionFailed, cancel: cancel},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+
+	_, err = client.UpdateMetadata(ctx, testImageUUID,
+		func(current []byte) ([]byte, error) {
+			calls++
+
+			fake.AddVersion(testImageUUID, testImageVersion(
+				"image", fmt.Sprintf("<newsItem>%d</newsItem>", calls)))
+
+			return append(current, '\n'), nil
+		})
+	if !errors.Is(err, context.Canceled) || !oc.IsPreconditionFailed(err) {
+		t.Fatalf("expected the backoff to be cancelled after a failed attempt, got: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected no attempts after cancellation, got %d calls", calls)
+	}
+}
+
+func TestClient_UpdateMetadata__Unchanged(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	version := fake.AddVersion(testImageUUID, testImageVersion("image", "<newsItem/>"))
+
+	res, err := client.UpdateMetadata(context.Background(), testImageUUID,
+		func(current []byte) ([]byte, error) {
+			return current, nil
+		})
+	if err != nil {
+		t.Fatalf("failed to update metadata: %v", err)
+	}
+
+	if res.Version != version {
+		t.Errorf("expected no new version to be created, got version %d", res.Version)
+	}
+}
@@ -0,0 +1,265 @@
+package newsml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Item classes and publication statuses used by the builders.
+const (
+	ItemClassPicture = "ninat:picture"
+	ItemClassText    = "ninat:text"
+	ItemClassConcept = "cinat:concept"
+
+	PubStatusDraft  = "imext:draft"
+	PubStatusUsable = "stat:usable"
+)
+
+// Image describes an image for NewImage.
+type Image struct {
+	// GUID is the UUID of the object, generated if empty.
+	GUID     string
+	FileName string
+	Mimetype string
+	Width    int
+	Height   int
+	Caption  string
+	Byline   string
+	Credit   string
+	// Created is when the picture was taken, defaults to now.
+	Created  time.Time
+	Keywords []string
+}
+
+// NewImage creates the picture metadata for an image file.
+func NewImage(img Image) *NewsItem {
+	item := newNewsItem(img.GUID, ItemClassPicture, img.Created)
+
+	item.ItemMeta.FileName = img.FileName
+	item.ContentMeta.Keywords = img.Keywords
+
+	data := ObjectData{}
+
+	data.Set("width", strconv.Itoa(img.Width))
+	data.Set("height", strconv.Itoa(img.Height))
+
+	optional := []struct{ name, value string }{
+		{"text", img.Caption},
+		{"byline", img.Byline},
+		{"credit", img.Credit},
+		{"objectName", img.FileName},
+		{"mimeType", img.Mimetype},
+	}
+
+	for _, f := range optional {
+		if f.value != "" {
+			data.Set(f.name, f.value)
+		}
+	}
+
+	item.ContentMeta.Metadata = &Metadata{
+		Objects: []Object{{
+			ID:   item.GUID,
+			Type: "x-im/image",
+			Data: &data,
+		}},
+	}
+
+	return item
+}
+
+// Article describes an article for NewArticle.
+type Article struct {
+	// GUID is the UUID of the object, generated if empty.
+	GUID     string
+	Headline string
+	// Body is the body text, one entry per paragraph.
+	Body []string
+	// Language is the language code of the text, like "en".
+	Language string
+	// Status is the publication status qcode, defaults to draft.
+	Status string
+	// Created defaults to now.
+	Created time.Time
+}
+
+// NewArticle creates an article with its text as an IDF document.
+func NewArticle(a Article) *NewsItem {
+	item := newNewsItem(a.GUID, ItemClassText, a.Created)
+
+	if a.Status != "" {
+		item.ItemMeta.PubStatus.QCode = a.Status
+	}
+
+	item.ItemMeta.Title = a.Headline
+	item.ContentMeta.Headline = a.Headline
+
+	idf := idfDocument{
+		Lang: a.Language,
+		Group: idfGroup{
+			ID:   uuid.NewString(),
+			Type: "body",
+		},
+	}
+
+	if a.Headline != "" {
+		idf.Group.Elements = append(idf.Group.Elements, idfElement{
+			ID:   uuid.NewString(),
+			Type: "headline",
+			Text: a.Headline,
+		})
+	}
+
+	for _, p := range a.Body {
+		idf.Group.Elements = append(idf.Group.Elements, idfElement{
+			ID:   uuid.NewString(),
+			Type: "body",
+			Text: p,
+		})
+	}
+
+	var buf bytes.Buffer
+
+	// Can only fail on invalid struct definitions.
+	_ = xml.NewEncoder(&buf).Encode(idf)
+
+	item.ContentSet = &ContentSet{
+		InlineXML: &InlineXML{
+			ContentType: "application/vnd.infomaker.idf+xml",
+			Content:     buf.String(),
+		},
+	}
+
+	return item
+}
+
+type idfDocument struct {
+	XMLName xml.Name `xml:"http://www.infomaker.se/idf/1.0 idf"`
+	Lang    string   `xml:"xml:lang,attr,omitempty"`
+	Group   idfGroup `xml:"group"`
+}
+
+type idfGroup struct {
+	ID       string       `xml:"id,attr"`
+	Type     string       `xml:"type,attr"`
+	Elements []idfElement `xml:"element"`
+}
+
+type idfElement struct {
+	ID   string `xml:"id,attr"`
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// ConceptInfo describes a concept for NewConcept.
+type ConceptInfo struct {
+	// GUID is the UUID of the object, generated if empty.
+	GUID string
+	Name string
+	// Type is the concept type qcode, like "cpnat:person".
+	Type string
+	// ImType is the Infomaker concept type, like "x-im/author".
+	ImType     string
+	Definition string
+	// Created defaults to now.
+	Created time.Time
+}
+
+// NewConcept creates a concept item.
+func NewConcept(ci ConceptInfo) *ConceptItem {
+	if ci.GUID == "" {
+		ci.GUID = uuid.NewString()
+	}
+
+	created := NewTruncatedDateTime(createdOrNow(ci.Created))
+
+	item := ConceptItem{
+		Conformance:     "power",
+		GUID:            ci.GUID,
+		Standard:        "NewsML-G2",
+		StandardVersion: "2.20",
+		Version:         "1",
+		CatalogRefs:     defaultCatalogs(),
+		ItemMeta: ItemMeta{
+			ItemClass:      QCode{QCode: ItemClassConcept},
+			VersionCreated: &created,
+			FirstCreated:   &created,
+			Provider:       &QCode{},
+			PubStatus:      &QCode{QCode: PubStatusUsable},
+			Title:          ci.Name,
+		},
+		Concept: Concept{
+			ConceptID: QCode{QCode: "im:" + ci.GUID},
+			Names:     []string{ci.Name},
+		},
+	}
+
+	if ci.Type != "" {
+		item.Concept.Type = &QCode{QCode: ci.Type}
+	}
+
+	if ci.Definition != "" {
+		item.Concept.Definitions = []Definition{{
+			Role: "drol:short",
+			Text: ci.Definition,
+		}}
+	}
+
+	if ci.ImType != "" {
+		item.Concept.Metadata = &Metadata{
+			Objects: []Object{{
+				ID:   ci.GUID,
+				Type: ci.ImType,
+			}},
+		}
+	}
+
+	return &item
+}
+
+func newNewsItem(guid string, itemClass string, created time.Time) *NewsItem {
+	if guid == "" {
+		guid = uuid.NewString()
+	}
+
+	dt := NewTruncatedDateTime(createdOrNow(created))
+
+	return &NewsItem{
+		Conformance:     "power",
+		GUID:            guid,
+		Standard:        "NewsML-G2",
+		StandardVersion: "2.26",
+		Version:         "1",
+		CatalogRefs:     defaultCatalogs(),
+		ItemMeta: ItemMeta{
+			ItemClass:      QCode{QCode: itemClass},
+			VersionCreated: &dt,
+			FirstCreated:   &dt,
+			Provider:       &QCode{},
+			PubStatus:      &QCode{QCode: PubStatusDraft},
+		},
+		ContentMeta: ContentMeta{
+			ContentCreated:  &dt,
+			ContentModified: &dt,
+		},
+	}
+}
+
+func createdOrNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now().UTC().Truncate(time.Second)
+	}
+
+	return t
+}
+
+func defaultCatalogs() []CatalogRef {
+	return []CatalogRef{
+		{Href: CatalogIPTC},
+		{Href: CatalogInfomaker},
+	}
+}
@@ -0,0 +1,117 @@
+package newsml_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/navigacontentlab/oc-client-go/v2/newsml"
+)
+
+func TestNewImage(t *testing.T) {
+	created := time.Date(2019, 8, 8, 14, 14, 22, 0, time.UTC)
+
+	item := newsml.NewImage(newsml.Image{
+		GUID:     "1c5c8a53-8e31-5a4a-91a2-bcaf3d460a6e",
+		FileName: "sample.jpeg",
+		Mimetype: "image/jpeg",
+		Width:    640,
+		Height:   427,
+		Caption:  "A sample <image>",
+		Credit:   "Example",
+		Created:  created,
+		Keywords: []string{"sample"},
+	})
+
+	data, err := item.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal image: %v", err)
+	}
+
+	parsed, err := newsml.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse image metadata: %v\n%s", err, data)
+	}
+
+	image := parsed.Object("x-im/image")
+	if image == nil {
+		t.Fatalf("missing image object:\n%s", data)
+	}
+
+	for name, want := range map[string]string{
+		"width":      "640",
+		"height":     "427",
+		"text":       "A sample <image>",
+		"credit":     "Example",
+		"objectName": "sample.jpeg",
+		"mimeType":   "image/jpeg",
+	} {
+		if got := image.Data.Get(name); got != want {
+			t.Errorf("expected %s to be %q, got %q", name, want, got)
+		}
+	}
+
+	if !parsed.ContentMeta.ContentCreated.Time.Equal(created) {
+		t.Errorf("unexpected created time %v", parsed.ContentMeta.ContentCreated)
+	}
+}
+
+func TestNewArticle(t *testing.T) {
+	item := newsml.NewArticle(newsml.Article{
+		Headline: "Headline",
+		Body:     []string{"First paragraph.", "Second & last."},
+		Language: "en",
+	})
+
+	data, err := item.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal article: %v", err)
+	}
+
+	parsed, err := newsml.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse article: %v\n%s", err, data)
+	}
+
+	content := parsed.ContentSet.InlineXML.Content
+
+	for _, want := range []string{
+		`xmlns="http://www.infomaker.se/idf/1.0"`,
+		`xml:lang="en"`,
+		`<element id="`,
+		`Second &amp; last.`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected the IDF to contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestNewConcept(t *testing.T) {
+	item := newsml.NewConcept(newsml.ConceptInfo{
+		Name:       "Jane Doe",
+		Type:       "cpnat:person",
+		ImType:     "x-im/author",
+		Definition: "Staff photographer",
+	})
+
+	item.AddLink(newsml.Link{Rel: "subject", Type: "x-im/category", UUID: "cat-1"})
+
+	data, err := item.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal concept: %v", err)
+	}
+
+	parsed, err := newsml.ParseConcept(data)
+	if err != nil {
+		t.Fatalf("failed to parse concept: %v\n%s", err, data)
+	}
+
+	if parsed.Concept.Names[0] != "Jane Doe" || parsed.Concept.Type.QCode != "cpnat:person" {
+		t.Errorf("unexpected concept %+v", parsed.Concept)
+	}
+
+	if len(parsed.ItemMeta.Links.ByRel("subject")) != 1 {
+		t.Errorf("expected a subject link:\n%s", data)
+	}
+}
@@ -0,0 +1,98 @@
+package newsml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+// ConceptItem is a NewsML-G2 concept item, used for authors, places,
+// organisations, topics and other concepts.
+type ConceptItem struct {
+	XMLName         xml.Name     `xml:"http://iptc.org/std/nar/2006-10-01/ conceptItem"`
+	Conformance     string       `xml:"conformance,attr,omitempty"`
+	GUID            string       `xml:"guid,attr"`
+	Standard        string       `xml:"standard,attr,omitempty"`
+	StandardVersion string       `xml:"standardversion,attr,omitempty"`
+	Version         string       `xml:"version,attr,omitempty"`
+	Attrs           []xml.Attr   `xml:",any,attr"`
+	CatalogRefs     []CatalogRef `xml:"catalogRef"`
+	ItemMeta        ItemMeta     `xml:"itemMeta"`
+	Concept         Concept      `xml:"concept"`
+	Other           []Element    `xml:",any"`
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (c *ConceptItem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ConceptItem
+
+	var p plain
+
+	if err := d.DecodeElement(&p, &start); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	p.Attrs = withoutNamespaceDecls(p.Attrs)
+
+	*c = ConceptItem(p)
+
+	return nil
+}
+
+// Concept describes the concept of a concept item.
+type Concept struct {
+	ConceptID   QCode        `xml:"conceptId"`
+	Names       []string     `xml:"name"`
+	Type        *QCode       `xml:"type,omitempty"`
+	Definitions []Definition `xml:"definition,omitempty"`
+	Metadata    *Metadata    `xml:"http://www.infomaker.se/newsml/1.0 metadata,omitempty"`
+	Other       []Element    `xml:",any"`
+}
+
+// Definition is a definition of a concept, its role tells short and
+// long definitions apart.
+type Definition struct {
+	Role string `xml:"role,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// ParseConcept parses a concept item.
+func ParseConcept(data []byte) (*ConceptItem, error) {
+	var item ConceptItem
+
+	if err := xml.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse concept item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// Marshal returns the concept item as an XML document.
+func (c *ConceptItem) Marshal() ([]byte, error) {
+	return marshalDocument(c)
+}
+
+// AddLink adds a link to the item metadata.
+func (c *ConceptItem) AddLink(link Link) {
+	if c.ItemMeta.Links == nil {
+		c.ItemMeta.Links = &Links{}
+	}
+
+	c.ItemMeta.Links.Links = append(c.ItemMeta.Links.Links, link)
+}
+
+// File returns the concept item as a file for an upload request.
+func (c *ConceptItem) File(name string) (oc.File, error) {
+	data, err := c.Marshal()
+	if err != nil {
+		return oc.File{}, err
+	}
+
+	return oc.File{
+		Name:     name,
+		Reader:   bytes.NewReader(data),
+		Mimetype: MimetypeConcept,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package newsml
+
+import (
+	"fmt"
+	"time"
+)
+
+// DatePrecision is the precision of a TruncatedDateTime.
+type DatePrecision int
+
+const (
+	// PrecisionDateTime is a date and time.
+	PrecisionDateTime DatePrecision = iota
+	// PrecisionDate is a date without a time.
+	PrecisionDate
+	// PrecisionMonth is a year and month.
+	PrecisionMonth
+	// PrecisionYear is a year.
+	PrecisionYear
+)
+
+func (dp DatePrecision) String() string {
+	switch dp {
+	case PrecisionDateTime:
+		return "datetime"
+	case PrecisionDate:
+		return "date"
+	case PrecisionMonth:
+		return "month"
+	case PrecisionYear:
+		return "year"
+	default:
+		return "unknown"
+	}
+}
+
+// truncatedLayouts are the accepted layouts of truncated date times,
+// most precise first.
+var truncatedLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{time.RFC3339Nano, PrecisionDateTime},
+	{"2006-01-02T15:04:05.999999999", PrecisionDateTime},
+	{"2006-01-02Z07:00", PrecisionDate},
+	{"2006-01-02", PrecisionDate},
+	{"2006-01", PrecisionMonth},
+	{"2006", PrecisionYear},
+}
+
+// TruncatedDateTime is a NewsML-G2 date time that can be truncated to
+// a date, a month or a year, like "2024-05-01" or
+// "2024-05-01T10:00:00+02:00". Parsed values are written back exactly
+// as they were read unless they're changed.
+type TruncatedDateTime struct {
+	// Time is the start of the period for truncated values.
+	Time      time.Time
+	Precision DatePrecision
+
+	raw string
+}
+
+// NewTruncatedDateTime creates a date time with full precision.
+func NewTruncatedDateTime(t time.Time) TruncatedDateTime {
+	return TruncatedDateTime{Time: t, Precision: PrecisionDateTime}
+}
+
+// NewDate creates a date time that is truncated to the date.
+func NewDate(t time.Time) TruncatedDateTime {
+	return TruncatedDateTime{Time: t, Precision: PrecisionDate}
+}
+
+// ParseTruncatedDateTime parses a date time that can be truncated to
+// a date, a month or a year.
+func ParseTruncatedDateTime(value string) (TruncatedDateTime, error) {
+	for _, l := range truncatedLayouts {
+		t, err := time.Parse(l.layout, value)
+		if err != nil {
+			continue
+		}
+
+		return TruncatedDateTime{Time: t, Precision: l.precision, raw: value}, nil
+	}
+
+	return TruncatedDateTime{}, fmt.Errorf("invalid date time %q", value)
+}
+
+// String returns the value as it's written in documents.
+func (dt TruncatedDateTime) String() string {
+	if dt.raw != "" {
+		orig, err := ParseTruncatedDateTime(dt.raw)
+		if err == nil && orig.Precision == dt.Precision && orig.Time.Equal(dt.Time) {
+			return dt.raw
+		}
+	}
+
+	switch dt.Precision {
+	case PrecisionDate:
+		return dt.Time.Format("2006-01-02")
+	case PrecisionMonth:
+		return dt.Time.Format("2006-01")
+	case PrecisionYear:
+		return dt.Time.Format("2006")
+	default:
+		return dt.Time.Format(time.RFC3339Nano)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (dt TruncatedDateTime) MarshalText() ([]byte, error) {
+	return []byte(dt.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (dt *TruncatedDateTime) UnmarshalText(text []byte) error {
+	v, err := ParseTruncatedDateTime(string(text))
+	if err != nil {
+		return err
+	}
+
+	*dt = v
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package newsml_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/navigacontentlab/oc-client-go/v2/newsml"
+)
+
+func TestTruncatedDateTime(t *testing.T) {
+	for _, tc := range []struct {
+		Value     string
+		Precision newsml.DatePrecision
+		Time      time.Time
+	}{
+		{"2024-05-01T10:00:00.500+02:00", newsml.PrecisionDateTime,
+			time.Date(2024, 5, 1, 8, 0, 0, 500000000, time.UTC)},
+		{"2024-05-01T10:00:00", newsml.PrecisionDateTime,
+			time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"2024-05-01", newsml.PrecisionDate, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-01+02:00", newsml.PrecisionDate,
+			time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC)},
+		{"2024-05", newsml.PrecisionMonth, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024", newsml.PrecisionYear, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		dt, err := newsml.ParseTruncatedDateTime(tc.Value)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", tc.Value, err)
+
+			continue
+		}
+
+		if dt.Precision != tc.Precision || !dt.Time.Equal(tc.Time) {
+			t.Errorf("expected %q to be %v with %v precision, got %v with %v precision",
+				tc.Value, tc.Time, tc.Precision, dt.Time, dt.Precision)
+		}
+
+		if dt.String() != tc.Value {
+			t.Errorf("expected %q to be written back unchanged, got %q", tc.Value, dt.String())
+		}
+	}
+
+	if _, err := newsml.ParseTruncatedDateTime("yesterday"); err == nil {
+		t.Error("expected an invalid date time to fail parsing")
+	}
+
+	dt, _ := newsml.ParseTruncatedDateTime("2024-05-01")
+
+	dt.Time = dt.Time.AddDate(0, 0, 1)
+
+	if dt.String() != "2024-05-02" {
+		t.Errorf("expected a changed date to be formatted as a date, got %q", dt.String())
+	}
+}
+
+func TestParse__DateOnly(t *testing.T) {
+	doc := `<newsItem guid="abc" xmlns="http://iptc.org/std/nar/2006-10-01/">
+  <itemMeta>
+    <itemClass qcode="ninat:text"/>
+    <versionCreated>2024-05-01T10:00:00+02:00</versionCreated>
+    <firstCreated>2024-05-01</firstCreated>
+  </itemMeta>
+  <contentMeta>
+    <contentCreated>2024-05</contentCreated>
+  </contentMeta>
+</newsItem>`
+
+	item, err := newsml.Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+
+	if item.ItemMeta.FirstCreated.Precision != newsml.PrecisionDate {
+		t.Errorf("expected firstCreated to be a date, got %v", item.ItemMeta.FirstCreated.Precision)
+	}
+
+	out, err := item.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal document: %v", err)
+	}
+
+	for _, want := range []string{
+		"<versionCreated>2024-05-01T10:00:00+02:00</versionCreated>",
+		"<firstCreated>2024-05-01</firstCreated>",
+		"<contentCreated>2024-05</contentCreated>",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected the output to contain %q:\n%s", want, out)
+		}
+	}
+}
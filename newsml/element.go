@@ -0,0 +1,313 @@
+package newsml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Element is an XML element that isn't covered by the typed model.
+// Its attributes and raw contents are kept so that documents survive
+// a parse and marshal round trip.
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr
+	InnerXML string
+}
+
+// NewElement creates an element with a text value.
+func NewElement(name string, text string) Element {
+	var buf bytes.Buffer
+
+	_ = xml.EscapeText(&buf, []byte(text))
+
+	return Element{
+		XMLName:  xml.Name{Local: name},
+		InnerXML: buf.String(),
+	}
+}
+
+// Attr returns the value of an attribute, ignoring its namespace.
+func (e Element) Attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// Text returns the character data of the element and its children.
+func (e Element) Text() string {
+	dec := xml.NewDecoder(strings.NewReader(e.InnerXML))
+
+	var b strings.Builder
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// The contents were well-formed when parsed, so an
+			// error can only be the end of the contents, or
+			// undeclared prefixes that don't matter here.
+			return b.String()
+		}
+
+		if cd, ok := tok.(xml.CharData); ok {
+			b.Write(cd)
+		}
+	}
+}
+
+// UnmarshalXML implements xml.Unmarshaler. The contents are written
+// to InnerXML with every namespace that they use declared, so that
+// prefixes declared by enclosing elements keep working when the
+// element is written on its own.
+func (e *Element) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	w := innerWriter{
+		scopes: []namespaceScope{{defaultNS: start.Name.Space}},
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := d.Token()
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+
+			w.start(t)
+		case xml.EndElement:
+			depth--
+
+			if depth > 0 {
+				w.end()
+			}
+		default:
+			w.other(t)
+		}
+	}
+
+	e.XMLName = start.Name
+	e.Attrs = withoutNamespaceDecls(start.Attr)
+	e.InnerXML = w.buf.String()
+
+	return nil
+}
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// namespaceScope is the default namespace and the prefixes of the
+// declared namespaces at a point in a document.
+type namespaceScope struct {
+	defaultNS string
+	prefixes  map[string]string
+}
+
+// innerWriter writes resolved tokens as XML, declaring namespaces
+// where they are first used.
+type innerWriter struct {
+	buf    strings.Builder
+	scopes []namespaceScope
+	names  []string
+}
+
+func (w *innerWriter) start(t xml.StartElement) {
+	parent := w.scopes[len(w.scopes)-1]
+
+	scope := namespaceScope{
+		defaultNS: parent.defaultNS,
+		prefixes:  make(map[string]string, len(parent.prefixes)),
+	}
+
+	for url, prefix := range parent.prefixes {
+		scope.prefixes[url] = prefix
+	}
+
+	var decls []xml.Attr
+
+	for _, a := range t.Attr {
+		switch {
+		case a.Name.Space == "xmlns":
+			scope.prefixes[a.Value] = a.Name.Local
+			decls = append(decls, xml.Attr{Name: xml.Name{Local: "xmlns:" + a.Name.Local}, Value: a.Value})
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			scope.defaultNS = a.Value
+			decls = append(decls, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: a.Value})
+		}
+	}
+
+	name := t.Name.Local
+
+	if t.Name.Space != scope.defaultNS {
+		if prefix, ok := scope.prefixes[t.Name.Space]; ok {
+			name = prefix + ":" + name
+		} else {
+			scope.defaultNS = t.Name.Space
+			decls = append(decls, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: t.Name.Space})
+		}
+	}
+
+	var attrs []xml.Attr
+
+	for _, a := range withoutNamespaceDecls(t.Attr) {
+		attrName := a.Name.Local
+
+		switch prefix, ok := scope.prefixes[a.Name.Space]; {
+		case a.Name.Space == "":
+		case a.Name.Space == xmlNamespace:
+			attrName = "xml:" + attrName
+		case ok:
+			attrName = prefix + ":" + attrName
+		default:
+			prefix = scope.newPrefix()
+			scope.prefixes[a.Name.Space] = prefix
+			decls = append(decls, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: a.Name.Space})
+			attrName = prefix + ":" + attrName
+		}
+
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: attrName}, Value: a.Value})
+	}
+
+	w.buf.WriteString("<" + name)
+
+	for _, a := range append(decls, attrs...) {
+		w.buf.WriteString(" " + a.Name.Local + `="` + attrEscaper.Replace(a.Value) + `"`)
+	}
+
+	w.buf.WriteString(">")
+
+	w.scopes = append(w.scopes, scope)
+	w.names = append(w.names, name)
+}
+
+func (w *innerWriter) end() {
+	name := w.names[len(w.names)-1]
+
+	w.names = w.names[:len(w.names)-1]
+	w.scopes = w.scopes[:len(w.scopes)-1]
+
+	w.buf.WriteString("</" + name + ">")
+}
+
+func (w *innerWriter) other(tok xml.Token) {
+	switch t := tok.(type) {
+	case xml.CharData:
+		w.buf.WriteString(textEscaper.Replace(string(t)))
+	case xml.Comment:
+		w.buf.WriteString("<!--" + string(t) + "-->")
+	case xml.ProcInst:
+		w.buf.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+	case xml.Directive:
+		w.buf.WriteString("<!" + string(t) + ">")
+	}
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+		"\r", "&#xD;", "\n", "&#xA;", "\t", "&#x9;")
+)
+
+// newPrefix returns a prefix that isn't used in the scope.
+func (s namespaceScope) newPrefix() string {
+	used := make(map[string]bool, len(s.prefixes))
+
+	for _, prefix := range s.prefixes {
+		used[prefix] = true
+	}
+
+	for n := 1; ; n++ {
+		if prefix := "ns" + strconv.Itoa(n); !used[prefix] {
+			return prefix
+		}
+	}
+}
+
+// MarshalXML implements xml.Marshaler.
+func (e Element) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{
+		Name: e.XMLName,
+		Attr: e.Attrs,
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	// The encoder has no way of writing raw XML, so the contents
+	// are written as tokens.
+	dec := xml.NewDecoder(strings.NewReader(e.InnerXML))
+
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid contents of %s: %w", e.XMLName.Local, err)
+		}
+
+		if err := enc.EncodeToken(namespaceFreeToken(tok)); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	return enc.EncodeToken(start.End()) //nolint:wrapcheck
+}
+
+// namespaceFreeToken converts raw tokens, where prefixes are kept in
+// Name.Space, so that the encoder writes them back as they were read.
+func namespaceFreeToken(tok xml.Token) xml.Token {
+	switch t := tok.(type) {
+	case xml.StartElement:
+		t.Name = prefixedName(t.Name)
+
+		attrs := make([]xml.Attr, len(t.Attr))
+
+		for i, a := range t.Attr {
+			attrs[i] = xml.Attr{Name: prefixedName(a.Name), Value: a.Value}
+		}
+
+		t.Attr = attrs
+
+		return t
+	case xml.EndElement:
+		t.Name = prefixedName(t.Name)
+
+		return t
+	default:
+		return xml.CopyToken(tok)
+	}
+}
+
+func prefixedName(n xml.Name) xml.Name {
+	if n.Space == "" {
+		return n
+	}
+
+	return xml.Name{Local: n.Space + ":" + n.Local}
+}
+
+// withoutNamespaceDecls drops namespace declarations, the encoder
+// declares the namespaces of element and attribute names itself.
+func withoutNamespaceDecls(attrs []xml.Attr) []xml.Attr {
+	var res []xml.Attr
+
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+
+		res = append(res, a)
+	}
+
+	return res
+}
@@ -0,0 +1,281 @@
+// Package newsml models the NewsML-G2 documents that Open Content
+// uses as object metadata, including the Infomaker extensions.
+//
+// Parsing is round trip safe: elements and attributes that aren't
+// part of the typed model are kept in Other and Attrs fields and are
+// written back when the document is marshalled.
+package newsml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+// XML namespaces used in the documents.
+const (
+	NamespaceNAR       = "http://iptc.org/std/nar/2006-10-01/"
+	NamespaceInfomaker = "http://www.infomaker.se/newsml/1.0"
+	NamespaceIDF       = "http://www.infomaker.se/idf/1.0"
+)
+
+// Metadata mimetypes used by OC to identify the kind of document.
+const (
+	MimetypePicture = "application/vnd.iptc.g2.newsitem+xml.picture"
+	MimetypeArticle = "application/vnd.iptc.g2.newsitem+xml.editorservice"
+	MimetypeConcept = "application/vnd.iptc.g2.conceptitem+xml"
+)
+
+// Catalogs referenced by documents created by the builders.
+const (
+	CatalogIPTC      = "http://www.iptc.org/std/catalog/catalog.IPTC-G2-Standards_30.xml"
+	CatalogInfomaker = "http://infomaker.se/spec/catalog/catalog.infomaker.g2.1_0.xml"
+)
+
+// NewsItem is a NewsML-G2 news item.
+type NewsItem struct {
+	XMLName         xml.Name     `xml:"http://iptc.org/std/nar/2006-10-01/ newsItem"`
+	Conformance     string       `xml:"conformance,attr,omitempty"`
+	GUID            string       `xml:"guid,attr"`
+	Standard        string       `xml:"standard,attr,omitempty"`
+	StandardVersion string       `xml:"standardversion,attr,omitempty"`
+	Version         string       `xml:"version,attr,omitempty"`
+	Attrs           []xml.Attr   `xml:",any,attr"`
+	CatalogRefs     []CatalogRef `xml:"catalogRef"`
+	ItemMeta        ItemMeta     `xml:"itemMeta"`
+	ContentMeta     ContentMeta  `xml:"contentMeta"`
+	ContentSet      *ContentSet  `xml:"contentSet,omitempty"`
+	Other           []Element    `xml:",any"`
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (n *NewsItem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain NewsItem
+
+	var p plain
+
+	if err := d.DecodeElement(&p, &start); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	p.Attrs = withoutNamespaceDecls(p.Attrs)
+
+	*n = NewsItem(p)
+
+	return nil
+}
+
+// CatalogRef references a controlled vocabulary catalog.
+type CatalogRef struct {
+	Href string `xml:"href,attr"`
+}
+
+// QCode is an element that references a concept by its qualified
+// code, like <itemClass qcode="ninat:picture"/>.
+type QCode struct {
+	QCode   string `xml:"qcode,attr,omitempty"`
+	Literal string `xml:"literal,attr,omitempty"`
+}
+
+// ItemMeta is the management metadata of an item.
+type ItemMeta struct {
+	ItemClass      QCode              `xml:"itemClass"`
+	VersionCreated *TruncatedDateTime `xml:"versionCreated,omitempty"`
+	FirstCreated   *TruncatedDateTime `xml:"firstCreated,omitempty"`
+	Provider       *QCode             `xml:"provider,omitempty"`
+	PubStatus      *QCode             `xml:"pubStatus,omitempty"`
+	Title          string             `xml:"title,omitempty"`
+	FileName       string             `xml:"fileName,omitempty"`
+	Links          *Links             `xml:"http://www.infomaker.se/newsml/1.0 links,omitempty"`
+	Other          []Element          `xml:",any"`
+}
+
+// ContentMeta is the descriptive metadata of an item.
+type ContentMeta struct {
+	ContentCreated  *TruncatedDateTime `xml:"contentCreated,omitempty"`
+	ContentModified *TruncatedDateTime `xml:"contentModified,omitempty"`
+	Headline        string             `xml:"headline,omitempty"`
+	Slugline        string             `xml:"slugline,omitempty"`
+	Description     string             `xml:"description,omitempty"`
+	Keywords        []string           `xml:"keyword,omitempty"`
+	Metadata        *Metadata          `xml:"http://www.infomaker.se/newsml/1.0 metadata,omitempty"`
+	Links           *Links             `xml:"http://www.infomaker.se/newsml/1.0 links,omitempty"`
+	Other           []Element          `xml:",any"`
+}
+
+// ContentSet holds the content of an item, for articles an IDF
+// document.
+type ContentSet struct {
+	InlineXML *InlineXML `xml:"inlineXML,omitempty"`
+	Other     []Element  `xml:",any"`
+}
+
+// InlineXML is inline XML content, kept as raw XML.
+type InlineXML struct {
+	ContentType string `xml:"contenttype,attr,omitempty"`
+	Content     string `xml:",innerxml"`
+}
+
+// Metadata is the Infomaker metadata extension element.
+type Metadata struct {
+	Objects []Object  `xml:"object"`
+	Other   []Element `xml:",any"`
+}
+
+// Object is an Infomaker metadata object, like the x-im/image object
+// that describes the file of an image.
+type Object struct {
+	ID    string      `xml:"id,attr,omitempty"`
+	Type  string      `xml:"type,attr,omitempty"`
+	UUID  string      `xml:"uuid,attr,omitempty"`
+	Title string      `xml:"title,attr,omitempty"`
+	Data  *ObjectData `xml:"data,omitempty"`
+	Links *Links      `xml:"links,omitempty"`
+	Other []Element   `xml:",any"`
+}
+
+// ObjectData holds the free form data fields of an object or link.
+type ObjectData struct {
+	Fields []Element `xml:",any"`
+}
+
+// Get returns the text of the first field with the given name.
+func (od *ObjectData) Get(name string) string {
+	if od == nil {
+		return ""
+	}
+
+	for _, f := range od.Fields {
+		if f.XMLName.Local == name {
+			return f.Text()
+		}
+	}
+
+	return ""
+}
+
+// Set sets the text of a field, replacing any existing field with the
+// same name.
+func (od *ObjectData) Set(name string, value string) {
+	field := NewElement(name, value)
+
+	for i, f := range od.Fields {
+		if f.XMLName.Local == name {
+			field.XMLName.Space = f.XMLName.Space
+			od.Fields[i] = field
+
+			return
+		}
+	}
+
+	od.Fields = append(od.Fields, field)
+}
+
+// Links is a list of Infomaker links.
+type Links struct {
+	Links []Link `xml:"link"`
+}
+
+// Link is a typed relationship to another object, concept or
+// resource.
+type Link struct {
+	Rel   string      `xml:"rel,attr,omitempty"`
+	Type  string      `xml:"type,attr,omitempty"`
+	UUID  string      `xml:"uuid,attr,omitempty"`
+	URI   string      `xml:"uri,attr,omitempty"`
+	URL   string      `xml:"url,attr,omitempty"`
+	Title string      `xml:"title,attr,omitempty"`
+	Data  *ObjectData `xml:"data,omitempty"`
+	Links *Links      `xml:"links,omitempty"`
+	Other []Element   `xml:",any"`
+}
+
+// ByRel returns the links with the given rel.
+func (l *Links) ByRel(rel string) []Link {
+	if l == nil {
+		return nil
+	}
+
+	var res []Link
+
+	for _, link := range l.Links {
+		if link.Rel == rel {
+			res = append(res, link)
+		}
+	}
+
+	return res
+}
+
+// Parse parses a news item.
+func Parse(data []byte) (*NewsItem, error) {
+	var item NewsItem
+
+	if err := xml.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse news item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// Marshal returns the news item as an XML document.
+func (n *NewsItem) Marshal() ([]byte, error) {
+	return marshalDocument(n)
+}
+
+// Object returns the first metadata object of the given type.
+func (n *NewsItem) Object(objectType string) *Object {
+	if n.ContentMeta.Metadata == nil {
+		return nil
+	}
+
+	for i := range n.ContentMeta.Metadata.Objects {
+		if n.ContentMeta.Metadata.Objects[i].Type == objectType {
+			return &n.ContentMeta.Metadata.Objects[i]
+		}
+	}
+
+	return nil
+}
+
+// AddLink adds a link to the item metadata.
+func (n *NewsItem) AddLink(link Link) {
+	if n.ItemMeta.Links == nil {
+		n.ItemMeta.Links = &Links{}
+	}
+
+	n.ItemMeta.Links.Links = append(n.ItemMeta.Links.Links, link)
+}
+
+// File returns the news item as a file for an upload request. The
+// mimetype is picked from the item class.
+func (n *NewsItem) File(name string) (oc.File, error) {
+	data, err := n.Marshal()
+	if err != nil {
+		return oc.File{}, err
+	}
+
+	mimetype := MimetypeArticle
+	if n.ItemMeta.ItemClass.QCode == "ninat:picture" {
+		mimetype = MimetypePicture
+	}
+
+	return oc.File{
+		Name:     name,
+		Reader:   bytes.NewReader(data),
+		Mimetype: mimetype,
+	}, nil
+}
+
+// marshalDocument doesn't indent the output as that would change the
+// text of mixed content in unknown elements.
+func marshalDocument(v interface{}) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,162 @@
+package newsml_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/navigacontentlab/oc-client-go/v2/newsml"
+)
+
+func TestParse(t *testing.T) {
+	data, err := os.ReadFile("../sample-image-metadata.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := newsml.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse sample: %v", err)
+	}
+
+	if item.GUID != "1c5c8a53-8e31-5a4a-91a2-bcaf3d460a6e" ||
+		item.ItemMeta.ItemClass.QCode != newsml.ItemClassPicture ||
+		item.ItemMeta.FileName != "sample.jpeg" {
+		t.Errorf("unexpected item metadata: %+v", item.ItemMeta)
+	}
+
+	image := item.Object("x-im/image")
+	if image == nil {
+		t.Fatal("expected an x-im/image object")
+	}
+
+	if image.Data.Get("width") != "640" || image.Data.Get("mimeType") != "image/jpeg" {
+		t.Errorf("unexpected image data: %+v", image.Data)
+	}
+}
+
+const unknownElementsDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<newsItem guid="abc" xml:lang="sv" custom="yes" xmlns="http://iptc.org/std/nar/2006-10-01/" xmlns:x="urn:example">
+  <itemMeta>
+    <itemClass qcode="ninat:text"/>
+    <edNote role="internal">Check <b>facts</b> &amp; figures</edNote>
+    <links xmlns="http://www.infomaker.se/newsml/1.0">
+      <link rel="author" type="x-im/author" uuid="u1" title="Jane">
+        <data><email>jane@example.com</email></data>
+      </link>
+    </links>
+  </itemMeta>
+  <contentMeta>
+    <headline>Hello</headline>
+    <x:extra x:kind="thing"><x:part x:n="1">value</x:part></x:extra>
+  </contentMeta>
+  <rightsInfo><copyrightHolder literal="Example"/></rightsInfo>
+</newsItem>`
+
+func TestRoundTrip(t *testing.T) {
+	item, err := newsml.Parse([]byte(unknownElementsDoc))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+
+	out, err := item.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal document: %v", err)
+	}
+
+	for _, want := range []string{
+		`custom="yes"`,
+		`<edNote xmlns="http://iptc.org/std/nar/2006-10-01/" role="internal">Check <b>facts</b> &amp; figures</edNote>`,
+		`<copyrightHolder literal="Example">`,
+		`jane@example.com</email>`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected the output to contain %q:\n%s", want, out)
+		}
+	}
+
+	again, err := newsml.Parse(out)
+	if err != nil {
+		t.Fatalf("failed to parse marshalled document: %v\n%s", err, out)
+	}
+
+	// Empty elements are written with end tags, so compare the
+	// output of two round trips.
+	out2, err := again.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal document again: %v", err)
+	}
+
+	if diff := cmp.Diff(string(out), string(out2)); diff != "" {
+		t.Errorf("round trip mismatch (-first +second):\n%s", diff)
+	}
+
+	authors := again.ItemMeta.Links.ByRel("author")
+	if len(authors) != 1 || authors[0].Data.Get("email") != "jane@example.com" {
+		t.Errorf("unexpected author links: %+v", authors)
+	}
+
+	extra := again.ContentMeta.Other[0]
+
+	if extra.XMLName != (xml.Name{Space: "urn:example", Local: "extra"}) ||
+		len(extra.Attrs) != 1 || extra.Attrs[0].Name != (xml.Name{Space: "urn:example", Local: "kind"}) {
+		t.Errorf("expected x:extra to keep its namespace, got %+v", extra)
+	}
+
+	if extra.Text() != "value" {
+		t.Errorf("unexpected unknown element: %+v", again.ContentMeta.Other)
+	}
+
+	// The element should keep working when it's written on its own,
+	// outside the scope where x was declared.
+	standalone, err := xml.Marshal(extra)
+	if err != nil {
+		t.Fatalf("failed to marshal x:extra: %v", err)
+	}
+
+	var parsed struct {
+		Part struct {
+			XMLName xml.Name
+			N       string `xml:"urn:example n,attr"`
+		} `xml:"urn:example part"`
+	}
+
+	if err := xml.Unmarshal(standalone, &parsed); err != nil {
+		t.Fatalf("failed to parse x:extra on its own: %v\n%s", err, standalone)
+	}
+
+	if part := parsed.Part; part.XMLName.Space != "urn:example" || part.N != "1" {
+		t.Errorf("expected x:part to keep its namespace, got %+v\n%s", part, standalone)
+	}
+}
+
+func TestNewsItem_File(t *testing.T) {
+	item := newsml.NewImage(newsml.Image{
+		FileName: "sample.jpeg",
+		Mimetype: "image/jpeg",
+		Width:    640,
+		Height:   427,
+	})
+
+	f, err := item.File("sample.xml")
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	if f.Mimetype != newsml.MimetypePicture {
+		t.Errorf("unexpected mimetype %q", f.Mimetype)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := buf.ReadFrom(f.Reader); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("<?xml")) {
+		t.Errorf("expected an XML document, got %q", buf.String())
+	}
+}
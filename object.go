@@ -206,17 +206,7 @@ type FileResponse struct {
 }
 
 func (c *Client) GetFile(ctx context.Context, uuid string, filename string, version int64) (*FileResponse, error) {
-	q := url.Values{}
-
-	if version != 0 {
-		q.Set("version", strconv.FormatInt(version, 10))
-	}
-
-	res, err := c.fetch(ctx, joinPath("objects", uuid, "files", filename), q)
-	if c.metrics != nil && res != nil {
-		c.metrics.incStatusCode(ctx, "objects", res.StatusCode)
-	}
-
+	res, err := c.fetchFile(ctx, uuid, filename, version)
 	if err != nil {
 		return nil, err
 	}
@@ -238,6 +228,23 @@ func (c *Client) GetFile(ctx context.Context, uuid string, filename string, vers
 	}, nil
 }
 
+func (c *Client) fetchFile(
+	ctx context.Context, uuid string, filename string, version int64, opts ...fetchOption,
+) (*http.Response, error) {
+	q := url.Values{}
+
+	if version != 0 {
+		q.Set("version", strconv.FormatInt(version, 10))
+	}
+
+	res, err := c.fetch(ctx, joinPath("objects", uuid, "files", filename), q, opts...)
+	if c.metrics != nil && res != nil {
+		c.metrics.incStatusCode(ctx, "objects", res.StatusCode)
+	}
+
+	return res, err
+}
+
 type FileList struct {
 	Version   int64
 	Primary   ObjectFile   `json:"primary"`
@@ -292,6 +299,12 @@ type ReplaceMetadataRequest struct {
 }
 
 func (c *Client) ReplaceMetadataFile(ctx context.Context, req ReplaceMetadataRequest) error {
+	_, err := c.replaceMetadataFile(ctx, req)
+
+	return err
+}
+
+func (c *Client) replaceMetadataFile(ctx context.Context, req ReplaceMetadataRequest) (http.Header, error) {
 	q := url.Values{}
 
 	if req.Batch {
@@ -305,7 +318,7 @@ func (c *Client) ReplaceMetadataFile(ctx context.Context, req ReplaceMetadataReq
 
 	r, err := http.NewRequest("PUT", reqURL, req.Body)
 	if err != nil {
-		return fmt.Errorf("could not create request: %w", err)
+		return nil, fmt.Errorf("could not create request: %w", err)
 	}
 
 	if req.Unit != "" {
@@ -320,14 +333,14 @@ func (c *Client) ReplaceMetadataFile(ctx context.Context, req ReplaceMetadataReq
 
 	resp, err := c.doRequest(ctx, r)
 	if err != nil {
-		return fmt.Errorf("failed to perform request: %w", err)
+		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return newResponseError(resp)
+		return nil, newResponseError(resp)
 	}
 
-	return discardAndClose(resp.Body)
+	return resp.Header, discardAndClose(resp.Body)
 }
 
 type DeleteMetadataRequest struct {
@@ -395,10 +408,16 @@ func (c *Client) GetMetadataFile(ctx context.Context, uuid string, version int)
 		return nil, newResponseError(res)
 	}
 
+	v, err := objectVersionFromHeader(res.Header, versionOptional)
+	if err != nil {
+		c.logger.Logf("failed to get version for metadata response: %v", err)
+	}
+
 	return &FileResponse{
 		ETag:        res.Header.Get("ETag"),
 		ContentType: res.Header.Get("Content-Type"),
 		Body:        res.Body,
+		Version:     v,
 	}, nil
 }
 
@@ -46,6 +46,14 @@ func (pv *PropertyValue) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (pv PropertyValue) MarshalJSON() ([]byte, error) {
+	if pv.NestedProperty != nil {
+		return json.Marshal(pv.NestedProperty) //nolint:wrapcheck
+	}
+
+	return json.Marshal(pv.Value) //nolint:wrapcheck
+}
+
 type PropertyList []*PropertyReference
 
 func (pl *PropertyList) AddProperty(name string, nested ...string) *PropertyReference {
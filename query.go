@@ -0,0 +1,49 @@
+package oc
+
+import (
+	"strings"
+	"time"
+)
+
+var queryEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// QueryTerm returns a query clause that matches a field value as a
+// quoted phrase, so that the value doesn't have to be escaped by the
+// caller.
+func QueryTerm(field string, value string) string {
+	return field + ":" + quoteQueryValue(value)
+}
+
+// QueryRange returns an inclusive range query clause for a field, an
+// empty from or to value leaves that end of the range open.
+func QueryRange(field string, from, to string) string {
+	bound := func(v string) string {
+		if v == "" {
+			return "*"
+		}
+
+		return quoteQueryValue(v)
+	}
+
+	return field + ":[" + bound(from) + " TO " + bound(to) + "]"
+}
+
+// QueryDateRange returns an inclusive range query clause for a date
+// field, a zero from or to time leaves that end of the range open.
+func QueryDateRange(field string, from, to time.Time) string {
+	return QueryRange(field, FormatQueryDate(from), FormatQueryDate(to))
+}
+
+// FormatQueryDate formats a time for use in a query, a zero time is
+// formatted as an empty string.
+func FormatQueryDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func quoteQueryValue(v string) string {
+	return `"` + queryEscaper.Replace(v) + `"`
+}
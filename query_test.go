@@ -0,0 +1,28 @@
+package oc_test
+
+import (
+	"testing"
+	"time"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestQueryHelpers(t *testing.T) {
+	from := time.Date(2021, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		Got  string
+		Want string
+	}{
+		{oc.QueryTerm("Headline", `say "hi" \o/`), `Headline:"say \"hi\" \\o/"`},
+		{oc.QueryRange("Pages", "1", "10"), `Pages:["1" TO "10"]`},
+		{oc.QueryRange("Pages", "", "10"), `Pages:[* TO "10"]`},
+		{oc.QueryDateRange("updated", from, time.Time{}), `updated:["2021-03-04T04:06:07Z" TO *]`},
+	}
+
+	for _, tc := range tests {
+		if tc.Got != tc.Want {
+			t.Errorf("got %s, want %s", tc.Got, tc.Want)
+		}
+	}
+}
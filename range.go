@@ -0,0 +1,468 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ByteRange is a range of bytes in a file. End is the offset of the
+// last byte in the range, a negative End reads to the end of the
+// file.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+func (br ByteRange) String() string {
+	if br.End < 0 {
+		return strconv.FormatInt(br.Start, 10) + "-"
+	}
+
+	return strconv.FormatInt(br.Start, 10) + "-" + strconv.FormatInt(br.End, 10)
+}
+
+func rangeHeader(ranges []ByteRange) string {
+	specs := make([]string, len(ranges))
+
+	for i := range ranges {
+		specs[i] = ranges[i].String()
+	}
+
+	return "bytes=" + strings.Join(specs, ",")
+}
+
+// ContentRange is a parsed Content-Range header.
+type ContentRange struct {
+	// Start is the offset of the first byte in the range.
+	Start int64
+	// End is the offset of the last byte in the range.
+	End int64
+	// Size is the total size of the file, or -1 if unknown.
+	Size int64
+}
+
+// ParseContentRange parses a Content-Range header in the form
+// "bytes start-end/size".
+func ParseContentRange(header string) (ContentRange, error) {
+	cr := ContentRange{Size: -1}
+
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return cr, fmt.Errorf("unsupported content range %q", header)
+	}
+
+	rangeSpec, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return cr, fmt.Errorf("invalid content range %q", header)
+	}
+
+	start, end, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return cr, fmt.Errorf("invalid content range %q", header)
+	}
+
+	var err error
+
+	cr.Start, err = strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return cr, fmt.Errorf("invalid content range start in %q: %w", header, err)
+	}
+
+	cr.End, err = strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return cr, fmt.Errorf("invalid content range end in %q: %w", header, err)
+	}
+
+	if size != "*" {
+		cr.Size, err = strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return cr, fmt.Errorf("invalid content range size in %q: %w", header, err)
+		}
+	}
+
+	return cr, nil
+}
+
+// FileRangeResponse is a response to a range request for a file.
+type FileRangeResponse struct {
+	FileResponse
+
+	// Partial is true if OC responded with 206 Partial Content. A
+	// server that doesn't support range requests returns the whole
+	// file.
+	Partial bool
+	// Range is the range of the file that is contained in Body.
+	Range ContentRange
+}
+
+// GetFileRange reads a byte range of a file.
+func (c *Client) GetFileRange(
+	ctx context.Context, uuid string, filename string, version int64, br ByteRange,
+) (*FileRangeResponse, error) {
+	return c.getFileRange(ctx, uuid, filename, version, br)
+}
+
+func (c *Client) getFileRange(
+	ctx context.Context, uuid string, filename string, version int64, br ByteRange,
+	opts ...fetchOption,
+) (*FileRangeResponse, error) {
+	opts = append(opts, fetchWithHeader("Range", rangeHeader([]ByteRange{br})))
+
+	res, err := c.fetchFile(ctx, uuid, filename, version, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	default:
+		return nil, newResponseError(res)
+	}
+
+	resp := FileRangeResponse{
+		FileResponse: FileResponse{
+			ETag:        res.Header.Get("ETag"),
+			ContentType: res.Header.Get("Content-Type"),
+			Body:        res.Body,
+		},
+		Partial: res.StatusCode == http.StatusPartialContent,
+	}
+
+	resp.Version, err = objectVersionFromHeader(res.Header, versionOptional)
+	if err != nil {
+		c.logger.Logf("failed to get version for file range response: %v", err)
+	}
+
+	if !resp.Partial {
+		resp.Range = ContentRange{Start: 0, End: res.ContentLength - 1, Size: res.ContentLength}
+
+		return &resp, nil
+	}
+
+	resp.Range, err = ParseContentRange(res.Header.Get("Content-Range"))
+	if err != nil {
+		safeClose(c.logger, "file range body", res.Body)
+
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// FilePart is a part of a multiple range response.
+type FilePart struct {
+	Range       ContentRange
+	ContentType string
+	Body        io.Reader
+}
+
+// FileRangesResponse is a response to a multiple range request for a
+// file. The parts are read using NextPart() and the response must be
+// closed when done.
+type FileRangesResponse struct {
+	ETag    string
+	Version int64
+	// Partial is true if OC responded with 206 Partial Content. A
+	// server that doesn't support range requests returns the whole
+	// file as a single part.
+	Partial bool
+
+	body   io.ReadCloser
+	reader *multipart.Reader
+	single *FilePart
+}
+
+// NextPart returns the next part of the response, or io.EOF when
+// there are no more parts. The body of the previous part can't be
+// read after NextPart() has been called.
+func (fr *FileRangesResponse) NextPart() (*FilePart, error) {
+	if fr.reader == nil {
+		part := fr.single
+		if part == nil {
+			return nil, io.EOF
+		}
+
+		fr.single = nil
+
+		return part, nil
+	}
+
+	part, err := fr.reader.NextPart()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	cr, err := ParseContentRange(part.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePart{
+		Range:       cr,
+		ContentType: part.Header.Get("Content-Type"),
+		Body:        part,
+	}, nil
+}
+
+// Close closes the response body.
+func (fr *FileRangesResponse) Close() error {
+	return fr.body.Close() //nolint:wrapcheck
+}
+
+// GetFileRanges reads several byte ranges of a file in one request.
+// OC can choose to coalesce overlapping ranges, so the parts that are
+// returned don't necessarily match the requested ranges.
+func (c *Client) GetFileRanges(
+	ctx context.Context, uuid string, filename string, version int64, ranges []ByteRange,
+) (*FileRangesResponse, error) {
+	if len(ranges) == 0 {
+		return nil, errors.New("no ranges specified")
+	}
+
+	res, err := c.fetchFile(ctx, uuid, filename, version,
+		fetchWithHeader("Range", rangeHeader(ranges)))
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	default:
+		return nil, newResponseError(res)
+	}
+
+	resp := FileRangesResponse{
+		ETag:    res.Header.Get("ETag"),
+		Partial: res.StatusCode == http.StatusPartialContent,
+		body:    res.Body,
+	}
+
+	resp.Version, err = objectVersionFromHeader(res.Header, versionOptional)
+	if err != nil {
+		c.logger.Logf("failed to get version for file ranges response: %v", err)
+	}
+
+	contentType := res.Header.Get("Content-Type")
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && resp.Partial && mediaType == "multipart/byteranges" {
+		resp.reader = multipart.NewReader(res.Body, params["boundary"])
+
+		return &resp, nil
+	}
+
+	part := FilePart{
+		ContentType: contentType,
+		Body:        res.Body,
+		Range: ContentRange{
+			Start: 0, End: res.ContentLength - 1, Size: res.ContentLength,
+		},
+	}
+
+	if resp.Partial {
+		part.Range, err = ParseContentRange(res.Header.Get("Content-Range"))
+		if err != nil {
+			safeClose(c.logger, "file ranges body", res.Body)
+
+			return nil, err
+		}
+	}
+
+	resp.single = &part
+
+	return &resp, nil
+}
+
+const defaultReadAhead = 1024 * 1024
+
+// FileReaderAt is an io.ReaderAt for a file in OC that is backed by
+// range requests. Reads are buffered with a read-ahead so that
+// sequential small reads don't result in a request each.
+type FileReaderAt struct {
+	client    *Client
+	ctx       context.Context //nolint:containedctx
+	uuid      string
+	filename  string
+	version   int64
+	size      int64
+	etag      string
+	readAhead int64
+
+	m        sync.Mutex
+	buf      []byte
+	bufStart int64
+}
+
+// NewFileReaderAt creates an io.ReaderAt for a file. The reader is
+// pinned to the version of the object that was current when it was
+// created if version is 0, and reads fail with an error wrapping
+// ErrConcurrentModification if the file changes after that. readAhead
+// sets the minimum number of bytes requested at a time, and defaults
+// to 1MiB. The context is used for all requests made by the reader.
+func (c *Client) NewFileReaderAt(
+	ctx context.Context, uuid string, filename string, version int64, readAhead int,
+) (*FileReaderAt, error) {
+	if readAhead <= 0 {
+		readAhead = defaultReadAhead
+	}
+
+	r := FileReaderAt{
+		client:    c,
+		ctx:       ctx,
+		uuid:      uuid,
+		filename:  filename,
+		version:   version,
+		readAhead: int64(readAhead),
+	}
+
+	res, err := c.GetFileRange(ctx, uuid, filename, version, ByteRange{
+		Start: 0, End: r.readAhead - 1,
+	})
+
+	// A range that starts at the first byte is only unsatisfiable if
+	// the file is empty.
+	var re *ResponseError
+
+	if errors.As(err, &re) && re.Response.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		r.etag = re.Response.Header.Get("ETag")
+
+		if r.version == 0 {
+			r.version, err = objectVersionFromHeader(re.Response.Header, versionOptional)
+			if err != nil {
+				c.logger.Logf("failed to get version for file range response: %v", err)
+			}
+		}
+
+		return &r, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer safeClose(c.logger, "file range body", res.Body)
+
+	if !res.Partial && res.Range.Size > r.readAhead {
+		return nil, errors.New("the server doesn't support range requests")
+	}
+
+	if res.Range.Size < 0 {
+		return nil, errors.New("the server didn't report the file size")
+	}
+
+	r.size = res.Range.Size
+	r.etag = res.ETag
+
+	if r.version == 0 {
+		r.version = res.Version
+	}
+
+	r.buf, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file range: %w", err)
+	}
+
+	return &r, nil
+}
+
+// Size returns the size of the file.
+func (r *FileReaderAt) Size() int64 {
+	return r.size
+}
+
+// ETag returns the ETag of the file.
+func (r *FileReaderAt) ETag() string {
+	return r.etag
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *FileReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	var n int
+
+	for n < len(p) && off < r.size {
+		if off >= r.bufStart && off < r.bufStart+int64(len(r.buf)) {
+			c := copy(p[n:], r.buf[off-r.bufStart:])
+
+			n += c
+			off += int64(c)
+
+			continue
+		}
+
+		err := r.fill(off, int64(len(p)-n))
+		if err != nil {
+			return n, err
+		}
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (r *FileReaderAt) fill(off int64, length int64) error {
+	end := off + max(length, r.readAhead) - 1
+	if end >= r.size {
+		end = r.size - 1
+	}
+
+	var opts []fetchOption
+
+	if r.etag != "" {
+		opts = append(opts, fetchWithHeader("If-Match", r.etag))
+	}
+
+	res, err := r.client.getFileRange(r.ctx, r.uuid, r.filename, r.version, ByteRange{
+		Start: off, End: end,
+	}, opts...)
+	if IsPreconditionFailed(err) {
+		return fmt.Errorf("the file has changed since the reader was created: %w: %w",
+			ErrConcurrentModification, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer safeClose(r.client.logger, "file range body", res.Body)
+
+	if !res.Partial || res.Range.Start != off {
+		return fmt.Errorf("the server responded with an unexpected range %d-%d",
+			res.Range.Start, res.Range.End)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read file range: %w", err)
+	}
+
+	if len(data) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	r.buf = data
+	r.bufStart = off
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package oc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestClient_GetFileRange(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("0123456789abcdef", "<newsItem/>"))
+
+	res, err := client.GetFileRange(context.Background(),
+		testImageUUID, "sample.jpeg", 0, oc.ByteRange{Start: 4, End: 7})
+	if err != nil {
+		t.Fatalf("failed to get file range: %v", err)
+	}
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "4567" {
+		t.Errorf("unexpected range contents %q", data)
+	}
+
+	want := oc.ContentRange{Start: 4, End: 7, Size: 16}
+
+	if diff := cmp.Diff(want, res.Range); diff != "" || !res.Partial {
+		t.Errorf("unexpected range (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_GetFileRanges(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("0123456789abcdef", "<newsItem/>"))
+
+	res, err := client.GetFileRanges(context.Background(),
+		testImageUUID, "sample.jpeg", 0, []oc.ByteRange{
+			{Start: 0, End: 1},
+			{Start: 10, End: -1},
+		})
+	if err != nil {
+		t.Fatalf("failed to get file ranges: %v", err)
+	}
+
+	defer res.Close()
+
+	var got []string
+
+	for {
+		part, err := res.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+
+		data, err := io.ReadAll(part.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if int64(len(data)) != part.Range.End-part.Range.Start+1 {
+			t.Errorf("part size %d doesn't match range %v", len(data), part.Range)
+		}
+
+		got = append(got, string(data))
+	}
+
+	if diff := cmp.Diff([]string{"01", "abcdef"}, got); diff != "" {
+		t.Errorf("unexpected parts (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_NewFileReaderAt(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	fake.AddVersion(testImageUUID, testImageVersion(string(data), "<newsItem/>"))
+
+	r, err := client.NewFileReaderAt(context.Background(),
+		testImageUUID, "sample.jpeg", 0, 128)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	if r.Size() != int64(len(data)) {
+		t.Errorf("expected size %d, got %d", len(data), r.Size())
+	}
+
+	start := fake.Requests
+
+	// Sequential small reads should be served from the read-ahead
+	// buffer.
+	for off := int64(0); off < 128; off += 16 {
+		buf := make([]byte, 16)
+
+		if _, err := r.ReadAt(buf, off); err != nil {
+			t.Fatalf("failed to read at %d: %v", off, err)
+		}
+	}
+
+	if fake.Requests != start {
+		t.Errorf("expected buffered reads, got %d extra requests", fake.Requests-start)
+	}
+
+	got, err := io.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+	if err != nil {
+		t.Fatalf("failed to read whole file: %v", err)
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Error("the contents read through the reader don't match the file")
+	}
+
+	buf := make([]byte, 10)
+
+	n, err := r.ReadAt(buf, r.Size()-5)
+	if n != 5 || !errors.Is(err, io.EOF) {
+		t.Errorf("expected a short read with io.EOF at the end, got %d, %v", n, err)
+	}
+}
+
+func TestClient_NewFileReaderAt__Empty(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("", "<newsItem/>"))
+
+	r, err := client.NewFileReaderAt(context.Background(),
+		testImageUUID, "sample.jpeg", 0, 128)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	if r.Size() != 0 {
+		t.Errorf("expected size 0, got %d", r.Size())
+	}
+
+	if r.ETag() == "" {
+		t.Error("expected the reader to have an ETag")
+	}
+
+	got, err := io.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+	if err != nil || len(got) != 0 {
+		t.Errorf("expected no data, got %q, %v", got, err)
+	}
+}
+
+func TestClient_NewFileReaderAt__Changed(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	fake.AddVersion(testImageUUID, testImageVersion(string(data), "<newsItem/>"))
+
+	r, err := client.NewFileReaderAt(context.Background(),
+		testImageUUID, "sample.jpeg", 0, 128)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	v, _ := fake.Version(testImageUUID, 1)
+
+	v.Files["sample.jpeg"] = fakeFile{
+		Mimetype: "image/jpeg",
+		Data:     bytes.Repeat([]byte("9876543210"), 100),
+	}
+
+	_, err = r.ReadAt(make([]byte, 16), 512)
+	if !errors.Is(err, oc.ErrConcurrentModification) {
+		t.Fatalf("expected a concurrent modification error, got: %v", err)
+	}
+
+	if !oc.IsPreconditionFailed(err) {
+		t.Error("expected the error to wrap the precondition failed response")
+	}
+}
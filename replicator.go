@@ -0,0 +1,605 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-log/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Eventlog event types.
+const (
+	EventAdd    = "ADD"
+	EventUpdate = "UPDATE"
+	EventDelete = "DELETE"
+)
+
+const (
+	defaultReplicationPollInterval = 5 * time.Second
+	defaultReplicationRetries      = 5
+	defaultReplicationRetryDelay   = time.Second
+)
+
+// CheckpointStore persists the ID of the last replicated event.
+type CheckpointStore interface {
+	LoadCheckpoint(ctx context.Context) (int, error)
+	SaveCheckpoint(ctx context.Context, eventID int) error
+}
+
+// ETagStore is an optional extension of a CheckpointStore that
+// records the ETag of every replicated object, so that conflicts can
+// be detected without walking the version history of the source.
+type ETagStore interface {
+	// LoadETag returns the recorded ETag of an object, or an empty
+	// string if there is none.
+	LoadETag(ctx context.Context, uuid string) (string, error)
+	// SaveETag records the ETag of an object, an empty ETag removes
+	// the record.
+	SaveETag(ctx context.Context, uuid string, etag string) error
+}
+
+// FileCheckpoint is a CheckpointStore that keeps the checkpoint in a
+// file. It's also an ETagStore that keeps one file per object in a
+// directory next to the checkpoint file.
+type FileCheckpoint struct {
+	Path string
+}
+
+// LoadCheckpoint implements CheckpointStore, a missing file means
+// that replication starts from the beginning of the eventlog.
+func (fc FileCheckpoint) LoadCheckpoint(_ context.Context) (int, error) {
+	data, err := os.ReadFile(fc.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	id, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint in %q: %w", fc.Path, err)
+	}
+
+	return id, nil
+}
+
+// SaveCheckpoint implements CheckpointStore.
+func (fc FileCheckpoint) SaveCheckpoint(_ context.Context, eventID int) error {
+	tmp := filepath.Join(filepath.Dir(fc.Path), "."+filepath.Base(fc.Path)+".tmp")
+
+	err := os.WriteFile(tmp, []byte(strconv.Itoa(eventID)), 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp, fc.Path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// LoadETag implements ETagStore.
+func (fc FileCheckpoint) LoadETag(_ context.Context, uuid string) (string, error) {
+	path, err := fc.etagPath(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read recorded ETag: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// SaveETag implements ETagStore.
+func (fc FileCheckpoint) SaveETag(_ context.Context, uuid string, etag string) error {
+	path, err := fc.etagPath(uuid)
+	if err != nil {
+		return err
+	}
+
+	if etag == "" {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove recorded ETag: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create ETag directory: %w", err)
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), "."+uuid+".tmp")
+
+	if err := os.WriteFile(tmp, []byte(etag), 0o600); err != nil {
+		return fmt.Errorf("failed to write recorded ETag: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace recorded ETag: %w", err)
+	}
+
+	return nil
+}
+
+func (fc FileCheckpoint) etagPath(uuid string) (string, error) {
+	if uuid == "" || uuid != filepath.Base(uuid) || strings.HasPrefix(uuid, ".") {
+		return "", fmt.Errorf("invalid object UUID %q", uuid)
+	}
+
+	return filepath.Join(fc.Path+".etags", uuid), nil
+}
+
+// ConflictPolicy decides what happens when the target object has
+// been changed independently of the source.
+type ConflictPolicy int
+
+const (
+	// ConflictSkip leaves the target object as it is.
+	ConflictSkip ConflictPolicy = iota
+	// ConflictOverwrite replaces the target object with the source
+	// object.
+	ConflictOverwrite
+)
+
+func (cp ConflictPolicy) String() string {
+	switch cp {
+	case ConflictSkip:
+		return "skip"
+	case ConflictOverwrite:
+		return "overwrite"
+	default:
+		return "unknown"
+	}
+}
+
+// ReplicationConflict describes an object that has been changed in
+// the target independently of the source.
+type ReplicationConflict struct {
+	Event         EventlogEvent
+	SourceVersion int64
+	SourceETag    string
+	TargetVersion int64
+	TargetETag    string
+}
+
+// ReplicatorOptions controls the behaviour of a Replicator.
+type ReplicatorOptions struct {
+	Source *Client
+	Target *Client
+	// Checkpoint stores the position in the source eventlog.
+	Checkpoint CheckpointStore
+	// PollInterval is the time to wait before polling the eventlog
+	// again when there are no new events. Defaults to five seconds.
+	PollInterval time.Duration
+	// MaxRetries is the number of times a failed event is retried
+	// before replication stops. Defaults to five.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, it doubles
+	// with every retry. Defaults to one second.
+	RetryDelay time.Duration
+	// Conflicts decides what to do with objects that have been
+	// changed in the target.
+	Conflicts ConflictPolicy
+	// OnConflict is called for every detected conflict. Optional.
+	OnConflict func(conflict ReplicationConflict)
+	// UploadSource is the source that objects are uploaded to the
+	// target with.
+	UploadSource string
+	// Unit is passed to the target as a X-Imid-Unit HTTP header.
+	Unit    string
+	Metrics *ReplicationMetrics
+	Logger  log.Logger
+}
+
+// Replicator follows the eventlog of a source OC and replays
+// additions, updates and deletions against a target OC. Objects are
+// always copied in their current state, so several updates can
+// result in a single new version in the target. Conflict detection
+// relies on ETags being derived from the object contents, so that a
+// copy has the same ETag as the original.
+type Replicator struct {
+	opts       ReplicatorOptions
+	logger     log.Logger
+	etags      ETagStore
+	checkpoint int
+	loaded     bool
+}
+
+// NewReplicator creates a new replicator.
+func NewReplicator(opts ReplicatorOptions) (*Replicator, error) {
+	if opts.Source == nil || opts.Target == nil {
+		return nil, errors.New("both a source and a target client are required")
+	}
+
+	if opts.Checkpoint == nil {
+		return nil, errors.New("a checkpoint store is required")
+	}
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = defaultReplicationPollInterval
+	}
+
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultReplicationRetries
+	}
+
+	if opts.RetryDelay == 0 {
+		opts.RetryDelay = defaultReplicationRetryDelay
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.DefaultLogger
+	}
+
+	etags, _ := opts.Checkpoint.(ETagStore)
+
+	return &Replicator{
+		opts:   opts,
+		logger: logger,
+		etags:  etags,
+	}, nil
+}
+
+// Run replicates events until the context is cancelled or an event
+// fails after all retries.
+func (r *Replicator) Run(ctx context.Context) error {
+	for {
+		n, err := r.Step(ctx)
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// Step replicates the next batch of events from the eventlog and
+// returns the number of replicated events.
+func (r *Replicator) Step(ctx context.Context) (int, error) {
+	if !r.loaded {
+		id, err := r.opts.Checkpoint.LoadCheckpoint(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+
+		r.checkpoint = id
+		r.loaded = true
+	}
+
+	events, err := r.opts.Source.Eventlog(ctx, r.checkpoint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read source eventlog: %w", err)
+	}
+
+	var n int
+
+	for _, event := range events {
+		if event.ID <= r.checkpoint {
+			continue
+		}
+
+		outcome, err := r.replicateWithRetries(ctx, event)
+		if err != nil {
+			return n, fmt.Errorf("failed to replicate event %d (%s %s): %w",
+				event.ID, event.EventType, event.UUID, err)
+		}
+
+		err = r.opts.Checkpoint.SaveCheckpoint(ctx, event.ID)
+		if err != nil {
+			return n, fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+
+		r.checkpoint = event.ID
+		n++
+
+		if r.opts.Metrics != nil {
+			r.opts.Metrics.observe(event, outcome)
+		}
+	}
+
+	return n, nil
+}
+
+// Checkpoint returns the ID of the last replicated event.
+func (r *Replicator) Checkpoint() int {
+	return r.checkpoint
+}
+
+const (
+	outcomeCopied   = "copied"
+	outcomeDeleted  = "deleted"
+	outcomeSkipped  = "skipped"
+	outcomeConflict = "conflict"
+)
+
+func (r *Replicator) replicateWithRetries(ctx context.Context, event EventlogEvent) (string, error) {
+	delay := r.opts.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		outcome, err := r.replicate(ctx, event)
+		if err == nil {
+			return outcome, nil
+		}
+
+		if attempt >= r.opts.MaxRetries || ctx.Err() != nil {
+			return "", err
+		}
+
+		r.logger.Logf("retrying event %d for %s: %v", event.ID, event.UUID, err)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err() //nolint:wrapcheck
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func (r *Replicator) replicate(ctx context.Context, event EventlogEvent) (string, error) {
+	switch event.EventType {
+	case EventAdd, EventUpdate:
+		return r.copyObject(ctx, event)
+	case EventDelete:
+		err := r.opts.Target.Delete(ctx, event.UUID, &DeleteOptions{
+			Unit: r.opts.Unit,
+		})
+		outcome := outcomeDeleted
+
+		switch {
+		case isNotFound(err):
+			outcome = outcomeSkipped
+		case err != nil:
+			return "", err
+		}
+
+		if err := r.recordETag(ctx, event.UUID, ""); err != nil {
+			return "", err
+		}
+
+		return outcome, nil
+	default:
+		return outcomeSkipped, nil
+	}
+}
+
+func (r *Replicator) copyObject(ctx context.Context, event EventlogEvent) (string, error) {
+	srcETag, srcVersion, err := r.opts.Source.headObject(ctx, event.UUID, 0)
+	if isNotFound(err) {
+		// Deleted since the event, the deletion will be
+		// replicated when we get to it.
+		return outcomeSkipped, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to check source object: %w", err)
+	}
+
+	target, err := r.opts.Target.CheckExists(ctx, event.UUID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check target object: %w", err)
+	}
+
+	if target.Exists && target.ETag == srcETag {
+		if err := r.recordETag(ctx, event.UUID, target.ETag); err != nil {
+			return "", err
+		}
+
+		return outcomeSkipped, nil
+	}
+
+	if target.Exists {
+		conflict, err := r.isConflict(ctx, event.UUID, target, srcVersion)
+		if err != nil {
+			return "", err
+		}
+
+		if conflict && !r.conflict(ReplicationConflict{
+			Event:         event,
+			SourceVersion: srcVersion,
+			SourceETag:    srcETag,
+			TargetVersion: target.Version,
+			TargetETag:    target.ETag,
+		}) {
+			return outcomeConflict, nil
+		}
+	}
+
+	pr, pw := io.Pipe()
+
+	exportErr := make(chan error, 1)
+
+	go func() {
+		_, err := r.opts.Source.ExportObject(ctx, event.UUID, srcVersion, pw)
+
+		_ = pw.CloseWithError(err)
+
+		exportErr <- err
+	}()
+
+	imported, importErr := r.opts.Target.ImportObject(ctx, pr, &ImportOptions{
+		Source:  r.opts.UploadSource,
+		Unit:    r.opts.Unit,
+		Batch:   true,
+		IfMatch: target.ETag,
+	})
+
+	_ = pr.CloseWithError(errors.New("import finished"))
+
+	if err := <-exportErr; err != nil {
+		return "", fmt.Errorf("failed to export source object: %w", err)
+	}
+
+	if IsPreconditionFailed(importErr) {
+		// Changed in the target while we were copying, the
+		// retry will take care of conflict detection.
+		return "", fmt.Errorf("target was modified during copy: %w", importErr)
+	}
+
+	if importErr != nil {
+		return "", importErr
+	}
+
+	if err := r.recordETag(ctx, event.UUID, imported.ETag); err != nil {
+		return "", err
+	}
+
+	return outcomeCopied, nil
+}
+
+// recordETag records the ETag of a replicated object if the
+// checkpoint store supports it.
+func (r *Replicator) recordETag(ctx context.Context, uuid string, etag string) error {
+	if r.etags == nil {
+		return nil
+	}
+
+	if err := r.etags.SaveETag(ctx, uuid, etag); err != nil {
+		return fmt.Errorf("failed to record replicated ETag: %w", err)
+	}
+
+	return nil
+}
+
+// replicationHistoryDepth is the number of earlier source versions
+// that are checked for a matching ETag when looking for conflicts.
+const replicationHistoryDepth = 20
+
+// isConflict checks if the target object has been changed by someone
+// else. The target is compared with the ETag that was recorded when
+// the object was last replicated. Objects without a recorded ETag are
+// compared with the recent source versions instead, version numbers
+// aren't comparable between instances, and a target that doesn't
+// match one of them has been changed independently. Source versions
+// that have been purged are treated as not matching.
+func (r *Replicator) isConflict(
+	ctx context.Context, uuid string, target *ExistsResponse, srcVersion int64,
+) (bool, error) {
+	if r.etags != nil {
+		recorded, err := r.etags.LoadETag(ctx, uuid)
+		if err != nil {
+			return false, fmt.Errorf("failed to load replicated ETag: %w", err)
+		}
+
+		if recorded != "" {
+			return target.ETag != recorded, nil
+		}
+	}
+
+	oldest := max(srcVersion-replicationHistoryDepth, 1)
+
+	for v := srcVersion - 1; v >= oldest; v-- {
+		etag, _, err := r.opts.Source.headObject(ctx, uuid, v)
+		if isNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("failed to check source version %d: %w", v, err)
+		}
+
+		if etag == target.ETag {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (r *Replicator) conflict(c ReplicationConflict) bool {
+	r.logger.Logf(
+		"conflict for %s: source is at version %d, target at version %d",
+		c.Event.UUID, c.SourceVersion, c.TargetVersion)
+
+	if r.opts.OnConflict != nil {
+		r.opts.OnConflict(c)
+	}
+
+	return r.opts.Conflicts == ConflictOverwrite
+}
+
+func isNotFound(err error) bool {
+	var re *ResponseError
+
+	return errors.As(err, &re) && re.Response.StatusCode == http.StatusNotFound
+}
+
+// ReplicationMetrics are prometheus metrics for a Replicator.
+type ReplicationMetrics struct {
+	Events      *prometheus.CounterVec
+	Lag         prometheus.Gauge
+	LastEventID prometheus.Gauge
+}
+
+// NewReplicationMetrics creates and registers replication metrics.
+func NewReplicationMetrics(reg prometheus.Registerer) (*ReplicationMetrics, error) {
+	events := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "oc_replication_events_total",
+		Help: "Replicated eventlog events.",
+	}, []string{"type", "outcome"})
+	if err := reg.Register(events); err != nil {
+		return nil, fmt.Errorf("failed to register metric: %w", err)
+	}
+
+	lag := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "oc_replication_lag_seconds",
+		Help: "Time between the creation of the last replicated event and its replication.",
+	})
+	if err := reg.Register(lag); err != nil {
+		return nil, fmt.Errorf("failed to register metric: %w", err)
+	}
+
+	lastID := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "oc_replication_last_event_id",
+		Help: "ID of the last replicated eventlog event.",
+	})
+	if err := reg.Register(lastID); err != nil {
+		return nil, fmt.Errorf("failed to register metric: %w", err)
+	}
+
+	return &ReplicationMetrics{
+		Events:      events,
+		Lag:         lag,
+		LastEventID: lastID,
+	}, nil
+}
+
+func (m *ReplicationMetrics) observe(event EventlogEvent, outcome string) {
+	m.Events.WithLabelValues(event.EventType, outcome).Inc()
+	m.LastEventID.Set(float64(event.ID))
+
+	if !event.Created.IsZero() {
+		m.Lag.Set(time.Since(event.Created).Seconds())
+	}
+}
@@ -0,0 +1,193 @@
+package oc_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const testOtherUUID = "24db3152-7479-58ed-9d87-c0b364ee68e9"
+
+func TestReplicator_Step(t *testing.T) {
+	source, sourceClient := newFakeOC(t)
+	target, targetClient := newFakeOC(t)
+
+	metrics, err := oc.NewReplicationMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conflicts []oc.ReplicationConflict
+
+	checkpoint := oc.FileCheckpoint{Path: filepath.Join(t.TempDir(), "checkpoint")}
+
+	newReplicator := func() *oc.Replicator {
+		r, err := oc.NewReplicator(oc.ReplicatorOptions{
+			Source:     sourceClient,
+			Target:     targetClient,
+			Checkpoint: checkpoint,
+			Metrics:    metrics,
+			OnConflict: func(c oc.ReplicationConflict) {
+				conflicts = append(conflicts, c)
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return r
+	}
+
+	source.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+	source.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+	source.AddVersion(testOtherUUID, testImageVersion("other", "<newsItem/>"))
+	source.Delete(testOtherUUID)
+
+	n, err := newReplicator().Step(context.Background())
+	if err != nil {
+		t.Fatalf("failed to replicate: %v", err)
+	}
+
+	if n != 4 {
+		t.Errorf("expected 4 replicated events, got %d", n)
+	}
+
+	sourceV, _ := source.Version(testImageUUID, 0)
+
+	targetV, targetVersion := target.Version(testImageUUID, 0)
+	if targetV == nil || targetV.etag() != sourceV.etag() {
+		t.Fatal("expected the target to have the current version of the source object")
+	}
+
+	// The two source versions should be coalesced into a single
+	// copy of the current version.
+	if targetVersion != 1 {
+		t.Errorf("expected a single target version, got %d", targetVersion)
+	}
+
+	if v, _ := target.Version(testOtherUUID, 0); v != nil {
+		t.Error("expected the deleted object to not exist in the target")
+	}
+
+	if got := testutil.ToFloat64(metrics.LastEventID); got != 4 {
+		t.Errorf("expected last event ID metric to be 4, got %v", got)
+	}
+
+	recorded, err := checkpoint.LoadETag(context.Background(), testImageUUID)
+	if err != nil || recorded != targetV.etag() {
+		t.Errorf("expected the replicated ETag to be recorded, got %q: %v", recorded, err)
+	}
+
+	if recorded, _ := checkpoint.LoadETag(context.Background(), testOtherUUID); recorded != "" {
+		t.Errorf("expected no recorded ETag for the deleted object, got %q", recorded)
+	}
+
+	// Resume from the checkpoint with a new replicator after the
+	// target has been changed by someone else.
+	target.AddVersion(testImageUUID, testImageVersion("local edit", "<newsItem/>"))
+	source.AddVersion(testImageUUID, testImageVersion("image v3", "<newsItem>v3</newsItem>"))
+
+	r := newReplicator()
+
+	n, err = r.Step(context.Background())
+	if err != nil {
+		t.Fatalf("failed to replicate: %v", err)
+	}
+
+	if n != 1 || r.Checkpoint() != 5 {
+		t.Errorf("expected to replicate event 5 only, replicated %d up to %d", n, r.Checkpoint())
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(conflicts))
+	}
+
+	if v, _ := target.Version(testImageUUID, 0); string(v.Files["sample.jpeg"].Data) != "local edit" {
+		t.Error("expected the conflicting target object to be left alone")
+	}
+}
+
+func TestReplicator_Step__TargetAhead(t *testing.T) {
+	source, sourceClient := newFakeOC(t)
+	target, targetClient := newFakeOC(t)
+
+	var conflicts []oc.ReplicationConflict
+
+	r, err := oc.NewReplicator(oc.ReplicatorOptions{
+		Source:     sourceClient,
+		Target:     targetClient,
+		Checkpoint: oc.FileCheckpoint{Path: filepath.Join(t.TempDir(), "checkpoint")},
+		OnConflict: func(c oc.ReplicationConflict) {
+			conflicts = append(conflicts, c)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The target has a longer history than the source, but its
+	// current version is a copy of the first source version.
+	target.AddVersion(testImageUUID, testImageVersion("old import", "<newsItem/>"))
+	target.AddVersion(testImageUUID, testImageVersion("old import", "<newsItem>2</newsItem>"))
+	target.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+
+	source.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+	source.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+
+	if _, err := r.Step(context.Background()); err != nil {
+		t.Fatalf("failed to replicate: %v", err)
+	}
+
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %d", len(conflicts))
+	}
+
+	if v, _ := target.Version(testImageUUID, 0); string(v.Files["sample.jpeg"].Data) != "image v2" {
+		t.Error("expected the target to be updated to the current source version")
+	}
+}
+
+func TestReplicator_Step__PurgedVersion(t *testing.T) {
+	source, sourceClient := newFakeOC(t)
+	target, targetClient := newFakeOC(t)
+
+	var conflicts []oc.ReplicationConflict
+
+	r, err := oc.NewReplicator(oc.ReplicatorOptions{
+		Source:     sourceClient,
+		Target:     targetClient,
+		Checkpoint: oc.FileCheckpoint{Path: filepath.Join(t.TempDir(), "checkpoint")},
+		OnConflict: func(c oc.ReplicationConflict) {
+			conflicts = append(conflicts, c)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The target has a copy of the first source version that wasn't
+	// made by the replicator, and the second source version has been
+	// purged.
+	target.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+
+	source.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+	source.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+	source.AddVersion(testImageUUID, testImageVersion("image v3", "<newsItem>v3</newsItem>"))
+	source.Purge(testImageUUID, 2)
+
+	if _, err := r.Step(context.Background()); err != nil {
+		t.Fatalf("failed to replicate: %v", err)
+	}
+
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %d", len(conflicts))
+	}
+
+	if v, _ := target.Version(testImageUUID, 0); string(v.Files["sample.jpeg"].Data) != "image v3" {
+		t.Error("expected the target to be updated to the current source version")
+	}
+}
@@ -0,0 +1,206 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RestoreOptions controls how an earlier version of an object is
+// restored.
+type RestoreOptions struct {
+	// Unit is passed to OC as a X-Imid-Unit HTTP header. Defaults
+	// to the unit of the restored version.
+	Unit string
+	// Source is the source that the restored version is uploaded
+	// with. Defaults to the source of the restored version.
+	Source string
+	// IfMatch causes the restore to fail unless the object still has
+	// a matching ETag. Defaults to the ETag of the object at the
+	// start of the restore.
+	IfMatch string
+}
+
+// RestoreVersion restores an object to an earlier version by
+// uploading the primary and metadata files of that version as a new
+// version of the object. The upload is conditional on the current
+// ETag, and an error wrapping ErrConcurrentModification is returned
+// if the object was changed during the restore. Returns the version
+// number of the restored object.
+func (c *Client) RestoreVersion(
+	ctx context.Context, uuid string, version int64, opts *RestoreOptions,
+) (int64, error) {
+	if version <= 0 {
+		return 0, errors.New("a version to restore must be specified")
+	}
+
+	var options RestoreOptions
+
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.IfMatch == "" {
+		exists, err := c.CheckExists(ctx, uuid)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get current ETag: %w", err)
+		}
+
+		if !exists.Exists {
+			return 0, fmt.Errorf("object %s does not exist", uuid)
+		}
+
+		options.IfMatch = exists.ETag
+	}
+
+	if options.Unit == "" || options.Source == "" {
+		err := c.defaultRestoreOrigin(ctx, uuid, version, &options)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	list, err := c.ListFiles(ctx, uuid, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files for version %d: %w", version, err)
+	}
+
+	files, closeFiles, err := c.versionFileSet(ctx, uuid, version, list)
+
+	defer closeFiles()
+
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := c.Upload(ctx, UploadRequest{
+		UUID:    uuid,
+		Source:  options.Source,
+		Files:   files,
+		Unit:    options.Unit,
+		IfMatch: options.IfMatch,
+	})
+	if IsPreconditionFailed(err) {
+		return 0, fmt.Errorf("failed to restore version %d: %w: %w",
+			version, ErrConcurrentModification, err)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore version %d: %w", version, err)
+	}
+
+	return res.Version, nil
+}
+
+// defaultRestoreOrigin sets the unit and source options that haven't
+// been set to the Unit and Source properties of the restored version.
+func (c *Client) defaultRestoreOrigin(
+	ctx context.Context, uuid string, version int64, options *RestoreOptions,
+) error {
+	props, err := c.PropertiesVersion(ctx, uuid, version, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get properties of version %d: %w", version, err)
+	}
+
+	for _, p := range props.Properties {
+		if len(p.Values) == 0 || p.Values[0].Value == "" {
+			continue
+		}
+
+		switch {
+		case p.Name == "Unit" && options.Unit == "":
+			options.Unit = p.Values[0].Value
+		case p.Name == "Source" && options.Source == "":
+			options.Source = p.Values[0].Value
+		}
+	}
+
+	return nil
+}
+
+// versionFileSet opens the primary and metadata files of an object
+// version for upload. The returned function closes all opened files
+// and must be called even if an error is returned.
+func (c *Client) versionFileSet(
+	ctx context.Context, uuid string, version int64, list *FileList,
+) (FileSet, func(), error) {
+	var bodies []io.Closer
+
+	closeAll := func() {
+		for i := range bodies {
+			safeClose(c.logger, "version file body", bodies[i])
+		}
+	}
+
+	if list.Primary.Name == "" {
+		return nil, closeAll, fmt.Errorf("version %d has no primary file", version)
+	}
+
+	files := make(FileSet)
+
+	add := func(field string, f ObjectFile) error {
+		res, err := c.GetFile(ctx, uuid, f.Name, version)
+		if err != nil {
+			return fmt.Errorf("failed to get file %q: %w", f.Name, err)
+		}
+
+		bodies = append(bodies, res.Body)
+
+		mimetype := f.Mimetype
+		if mimetype == "" {
+			mimetype = res.ContentType
+		}
+
+		files[field] = File{
+			Name:     f.Name,
+			Reader:   res.Body,
+			Mimetype: mimetype,
+		}
+
+		return nil
+	}
+
+	if err := add("file", list.Primary); err != nil {
+		return nil, closeAll, err
+	}
+
+	for i, f := range list.uploadMetadata() {
+		if err := add(metadataField(i), f); err != nil {
+			return nil, closeAll, err
+		}
+	}
+
+	return files, closeAll, nil
+}
+
+// uploadMetadata returns the metadata files that are uploaded
+// together with the primary file, in form field order. OC can list
+// the primary file among the metadata, it's only uploaded once.
+func (fl *FileList) uploadMetadata() []ObjectFile {
+	files := make([]ObjectFile, 0, len(fl.Metadata))
+	seen := map[string]bool{fl.Primary.Name: true}
+
+	for _, f := range fl.Metadata {
+		if seen[f.Name] {
+			continue
+		}
+
+		seen[f.Name] = true
+
+		files = append(files, f)
+	}
+
+	return files
+}
+
+// metadataField returns the upload form field name for the n:th
+// (zero-based) metadata file of an object.
+func metadataField(n int) string {
+	if n == 0 {
+		return "metadata"
+	}
+
+	return "metadata" + strconv.Itoa(n+1)
+}
@@ -0,0 +1,118 @@
+package oc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+const testImageUUID = "1c5c8a53-8e31-5a4a-91a2-bcaf3d460a6e"
+
+func testImageVersion(image, metadata string) *fakeVersion {
+	return &fakeVersion{
+		Primary:  "sample.jpeg",
+		Metadata: []string{"sample-image.metadata.xml"},
+		Files: map[string]fakeFile{
+			"sample.jpeg": {
+				Mimetype: "image/jpeg",
+				Data:     []byte(image),
+			},
+			"sample-image.metadata.xml": {
+				Mimetype: "application/vnd.iptc.g2.newsitem+xml.picture",
+				Data:     []byte(metadata),
+			},
+		},
+	}
+}
+
+func TestClient_RestoreVersion(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+	fake.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+
+	version, err := client.RestoreVersion(context.Background(), testImageUUID, 1, &oc.RestoreOptions{
+		Unit:   "editorial",
+		Source: "restore-test",
+	})
+	if err != nil {
+		t.Fatalf("failed to restore version: %v", err)
+	}
+
+	if version != 3 {
+		t.Errorf("expected the restored version to be 3, got %d", version)
+	}
+
+	restored, _ := fake.Version(testImageUUID, version)
+	original, _ := fake.Version(testImageUUID, 1)
+
+	if restored.etag() != original.etag() {
+		t.Error("expected the restored version to have the same content as version 1")
+	}
+
+	if restored.Unit != "editorial" || restored.Source != "restore-test" {
+		t.Errorf("expected unit and source to be preserved, got %q and %q",
+			restored.Unit, restored.Source)
+	}
+
+	meta := restored.Files["sample-image.metadata.xml"]
+	if !bytes.Equal(meta.Data, []byte("<newsItem>v1</newsItem>")) {
+		t.Errorf("unexpected restored metadata: %q", meta.Data)
+	}
+}
+
+func TestClient_RestoreVersion__DefaultOrigin(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	v1 := testImageVersion("image v1", "<newsItem>v1</newsItem>")
+	v1.Unit = "sports"
+	v1.Source = "photo-desk"
+
+	fake.AddVersion(testImageUUID, v1)
+	fake.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+
+	version, err := client.RestoreVersion(context.Background(), testImageUUID, 1, &oc.RestoreOptions{
+		Source: "restore-test",
+	})
+	if err != nil {
+		t.Fatalf("failed to restore version: %v", err)
+	}
+
+	restored, _ := fake.Version(testImageUUID, version)
+
+	if restored.Unit != "sports" {
+		t.Errorf("expected the unit of version 1 to be used, got %q", restored.Unit)
+	}
+
+	if restored.Source != "restore-test" {
+		t.Errorf("expected the source option to be used, got %q", restored.Source)
+	}
+}
+
+func TestClient_RestoreVersion__ConcurrentModification(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image v1", "<newsItem>v1</newsItem>"))
+
+	v1, _ := fake.Version(testImageUUID, 1)
+
+	fake.AddVersion(testImageUUID, testImageVersion("image v2", "<newsItem>v2</newsItem>"))
+
+	_, err := client.RestoreVersion(context.Background(), testImageUUID, 1, &oc.RestoreOptions{
+		IfMatch: v1.etag(),
+	})
+	if !errors.Is(err, oc.ErrConcurrentModification) {
+		t.Fatalf("expected a concurrent modification error, got: %v", err)
+	}
+
+	if !oc.IsPreconditionFailed(err) {
+		t.Error("expected the error to wrap the precondition failed response")
+	}
+
+	if fake.Uploads != 0 {
+		t.Errorf("expected no uploads to be accepted, got %d", fake.Uploads)
+	}
+}
@@ -0,0 +1,339 @@
+package oc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-log/log"
+)
+
+const (
+	defaultSchemaCheckInterval   = 30 * time.Second
+	defaultSchemaRefreshInterval = time.Hour
+)
+
+// SchemaCacheOptions controls the behaviour of a SchemaCache.
+type SchemaCacheOptions struct {
+	// CheckInterval is how often Run compares the checksum of the
+	// active OC configuration with the checksum of the cached
+	// schema. Defaults to thirty seconds.
+	CheckInterval time.Duration
+	// RefreshInterval is the maximum age of the cached schema, it's
+	// reloaded when it gets older even if the checksum hasn't
+	// changed. Defaults to one hour.
+	RefreshInterval time.Duration
+	Logger          log.Logger
+}
+
+// SchemaChange describes a change of the cached schema. Previous is
+// nil for the initial load.
+type SchemaChange struct {
+	Previous         *ContentTypesResponse
+	Current          *ContentTypesResponse
+	PreviousChecksum string
+	Checksum         string
+}
+
+// SchemaCache keeps a copy of the OC schema and reloads it when the
+// active configuration changes. It's safe for concurrent use. The
+// cached schema is shared, callers must not modify it.
+type SchemaCache struct {
+	client *Client
+	opts   SchemaCacheOptions
+	logger log.Logger
+
+	// loadMu serialises loads so that concurrent callers don't
+	// fetch the schema more than once.
+	loadMu sync.Mutex
+
+	m        sync.RWMutex
+	schema   *ContentTypesResponse
+	checksum string
+	loaded   time.Time
+
+	subMu   sync.Mutex
+	subs    map[int]func(SchemaChange)
+	nextSub int
+	// pending are the changes that haven't been delivered to the
+	// subscribers yet, notifying is set while they're delivered.
+	pending   []SchemaChange
+	notifying bool
+}
+
+// NewSchemaCache creates a schema cache. The schema is loaded on
+// first use.
+func NewSchemaCache(client *Client, opts SchemaCacheOptions) (*SchemaCache, error) {
+	if client == nil {
+		return nil, errors.New("a client is required")
+	}
+
+	if opts.CheckInterval == 0 {
+		opts.CheckInterval = defaultSchemaCheckInterval
+	}
+
+	if opts.RefreshInterval == 0 {
+		opts.RefreshInterval = defaultSchemaRefreshInterval
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.DefaultLogger
+	}
+
+	return &SchemaCache{
+		client: client,
+		opts:   opts,
+		logger: logger,
+		subs:   make(map[int]func(SchemaChange)),
+	}, nil
+}
+
+// Schema returns the cached schema, loading it if necessary.
+func (sc *SchemaCache) Schema(ctx context.Context) (*ContentTypesResponse, error) {
+	sc.m.RLock()
+	schema := sc.schema
+	sc.m.RUnlock()
+
+	if schema != nil {
+		return schema, nil
+	}
+
+	defer sc.notify()
+
+	sc.loadMu.Lock()
+	defer sc.loadMu.Unlock()
+
+	// Another caller could have loaded the schema while we waited.
+	sc.m.RLock()
+	schema = sc.schema
+	sc.m.RUnlock()
+
+	if schema != nil {
+		return schema, nil
+	}
+
+	return sc.load(ctx)
+}
+
+// Checksum returns the configuration checksum of the cached schema,
+// or an empty string if it hasn't been loaded.
+func (sc *SchemaCache) Checksum() string {
+	sc.m.RLock()
+	defer sc.m.RUnlock()
+
+	return sc.checksum
+}
+
+// Refresh reloads the schema unconditionally.
+func (sc *SchemaCache) Refresh(ctx context.Context) (*ContentTypesResponse, error) {
+	defer sc.notify()
+
+	sc.loadMu.Lock()
+	defer sc.loadMu.Unlock()
+
+	return sc.load(ctx)
+}
+
+// Check reloads the schema if the checksum of the active
+// configuration has changed, or if the cached schema is older than
+// the refresh interval. It reports whether the schema was reloaded.
+func (sc *SchemaCache) Check(ctx context.Context) (bool, error) {
+	defer sc.notify()
+
+	sc.loadMu.Lock()
+	defer sc.loadMu.Unlock()
+
+	sc.m.RLock()
+	stale := sc.schema == nil || time.Since(sc.loaded) >= sc.opts.RefreshInterval
+	current := sc.checksum
+	sc.m.RUnlock()
+
+	if !stale {
+		checksum, err := sc.activeChecksum(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		if checksum == current {
+			return false, nil
+		}
+	}
+
+	_, err := sc.load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Run checks for schema changes at the configured interval until
+// the context is cancelled. Failed checks are logged and retried at
+// the next interval, the cached schema is kept in the meantime.
+func (sc *SchemaCache) Run(ctx context.Context) error {
+	ticker := time.NewTicker(sc.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := sc.Check(ctx); err != nil && ctx.Err() == nil {
+			sc.logger.Logf("failed to check for schema changes: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-ticker.C:
+		}
+	}
+}
+
+// Subscribe registers a function that is called with every change of
+// the schema, including the initial load. Subscribers are called
+// after the load has completed, so they're free to call the other
+// methods of the cache. Changes are delivered in the order they were
+// loaded by one goroutine at a time, which can be another goroutine
+// than the one that loaded the change if a delivery is already in
+// progress. The returned function cancels the subscription.
+func (sc *SchemaCache) Subscribe(fn func(change SchemaChange)) func() {
+	sc.subMu.Lock()
+	defer sc.subMu.Unlock()
+
+	id := sc.nextSub
+	sc.nextSub++
+
+	sc.subs[id] = fn
+
+	return func() {
+		sc.subMu.Lock()
+		defer sc.subMu.Unlock()
+
+		delete(sc.subs, id)
+	}
+}
+
+// load fetches the checksum and the schema, the caller must hold
+// loadMu and call notify once it has been released. The checksum is
+// read first so that a configuration change during the load is
+// picked up by the next check.
+func (sc *SchemaCache) load(ctx context.Context) (*ContentTypesResponse, error) {
+	checksum, err := sc.activeChecksum(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := sc.client.ContentTypes(ctx, ContentTypesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load content types: %w", err)
+	}
+
+	sc.m.Lock()
+
+	change := SchemaChange{
+		Previous:         sc.schema,
+		Current:          schema,
+		PreviousChecksum: sc.checksum,
+		Checksum:         checksum,
+	}
+
+	sc.schema = schema
+	sc.checksum = checksum
+	sc.loaded = time.Now()
+
+	sc.m.Unlock()
+
+	if change.Checksum != change.PreviousChecksum ||
+		!reflect.DeepEqual(change.Previous, change.Current) {
+		sc.subMu.Lock()
+		sc.pending = append(sc.pending, change)
+		sc.subMu.Unlock()
+	}
+
+	return schema, nil
+}
+
+func (sc *SchemaCache) activeChecksum(ctx context.Context) (string, error) {
+	health, err := sc.client.Health(ctx, HealthRequest{
+		SkipIndexer: true,
+		SkipSolr:    true,
+		SkipStorage: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read configuration checksum: %w", err)
+	}
+
+	return health.ActiveConfiguration.Checksum, nil
+}
+
+// notify delivers pending changes to the subscribers. Only one
+// goroutine delivers changes at a time, changes that are added while
+// they're delivered are picked up by the delivering goroutine. A
+// panicking subscriber ends the delivery, the remaining changes are
+// delivered by the next call.
+func (sc *SchemaCache) notify() {
+	sc.subMu.Lock()
+
+	if sc.notifying {
+		sc.subMu.Unlock()
+
+		return
+	}
+
+	sc.notifying = true
+
+	sc.subMu.Unlock()
+
+	done := false
+
+	defer func() {
+		if done {
+			return
+		}
+
+		sc.subMu.Lock()
+		sc.notifying = false
+		sc.subMu.Unlock()
+	}()
+
+	for {
+		change, subs, ok := sc.nextChange()
+		if !ok {
+			done = true
+
+			return
+		}
+
+		for _, fn := range subs {
+			fn(change)
+		}
+	}
+}
+
+// nextChange takes the next pending change and the current
+// subscribers. The delivery is ended when there are no pending
+// changes, in the same critical section so that no change is left
+// behind.
+func (sc *SchemaCache) nextChange() (SchemaChange, []func(SchemaChange), bool) {
+	sc.subMu.Lock()
+	defer sc.subMu.Unlock()
+
+	if len(sc.pending) == 0 {
+		sc.notifying = false
+
+		return SchemaChange{}, nil, false
+	}
+
+	change := sc.pending[0]
+	sc.pending = sc.pending[1:]
+
+	subs := make([]func(SchemaChange), 0, len(sc.subs))
+
+	for _, fn := range sc.subs {
+		subs = append(subs, fn)
+	}
+
+	return change, subs, true
+}
@@ -0,0 +1,281 @@
+package oc_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func newSchemaTestCache(t *testing.T, opts oc.SchemaCacheOptions) (*fakeOC, *oc.SchemaCache) {
+	t.Helper()
+
+	fake, client := newFakeOC(t)
+
+	var schema oc.ContentTypesResponse
+
+	loadTestData(t, "contenttypesresponse.json", &schema)
+
+	fake.Schema = &schema
+	fake.ConfigChecksum = "v1"
+
+	cache, err := oc.NewSchemaCache(client, opts)
+	if err != nil {
+		t.Fatalf("failed to create schema cache: %v", err)
+	}
+
+	return fake, cache
+}
+
+func TestSchemaCache_Concurrent(t *testing.T) {
+	fake, cache := newSchemaTestCache(t, oc.SchemaCacheOptions{})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			schema, err := cache.Schema(ctx)
+			if err != nil {
+				t.Errorf("failed to get schema: %v", err)
+
+				return
+			}
+
+			if schema.Type("Article") == nil {
+				t.Error("expected the Article content type")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if fake.SchemaLoads != 1 {
+		t.Errorf("expected the schema to be loaded once, got %d loads", fake.SchemaLoads)
+	}
+
+	if cache.Checksum() != "v1" {
+		t.Errorf("unexpected checksum %q", cache.Checksum())
+	}
+}
+
+func TestSchemaCache_Check(t *testing.T) {
+	fake, cache := newSchemaTestCache(t, oc.SchemaCacheOptions{})
+	ctx := context.Background()
+
+	var changes []oc.SchemaChange
+
+	unsubscribe := cache.Subscribe(func(change oc.SchemaChange) {
+		changes = append(changes, change)
+	})
+
+	if _, err := cache.Schema(ctx); err != nil {
+		t.Fatalf("failed to get schema: %v", err)
+	}
+
+	if len(changes) != 1 || changes[0].Previous != nil || changes[0].Checksum != "v1" {
+		t.Fatalf("expected a notification of the initial load, got %+v", changes)
+	}
+
+	reloaded, err := cache.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+
+	if reloaded || fake.SchemaLoads != 1 {
+		t.Errorf("expected no reload with an unchanged checksum, got %d loads", fake.SchemaLoads)
+	}
+
+	fake.m.Lock()
+	fake.ConfigChecksum = "v2"
+	fake.Schema.ContentTypes = fake.Schema.ContentTypes[:1]
+	fake.m.Unlock()
+
+	reloaded, err = cache.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+
+	if !reloaded {
+		t.Fatal("expected a reload after a configuration change")
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected a change notification, got %d notifications", len(changes))
+	}
+
+	change := changes[1]
+
+	if change.PreviousChecksum != "v1" || change.Checksum != "v2" {
+		t.Errorf("unexpected checksums %q -> %q", change.PreviousChecksum, change.Checksum)
+	}
+
+	if len(change.Previous.ContentTypes) != 6 || len(change.Current.ContentTypes) != 1 {
+		t.Errorf("unexpected schemas in change notification")
+	}
+
+	unsubscribe()
+
+	fake.m.Lock()
+	fake.ConfigChecksum = "v3"
+	fake.m.Unlock()
+
+	if _, err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("failed to refresh schema: %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Error("expected no notifications after unsubscribing")
+	}
+}
+
+func TestSchemaCache_SubscriberReload(t *testing.T) {
+	fake, cache := newSchemaTestCache(t, oc.SchemaCacheOptions{})
+	ctx := context.Background()
+
+	var checksums []string
+
+	cache.Subscribe(func(change oc.SchemaChange) {
+		checksums = append(checksums, change.Checksum)
+
+		if change.Checksum != "v1" {
+			return
+		}
+
+		fake.m.Lock()
+		fake.ConfigChecksum = "v2"
+		fake.m.Unlock()
+
+		// Calling the cache from a subscriber must not deadlock.
+		if _, err := cache.Check(ctx); err != nil {
+			t.Errorf("failed to check schema: %v", err)
+		}
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if _, err := cache.Schema(ctx); err != nil {
+			t.Errorf("failed to get schema: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the schema")
+	}
+
+	if len(checksums) != 2 || checksums[0] != "v1" || checksums[1] != "v2" {
+		t.Errorf("expected the changes to be delivered in order, got %v", checksums)
+	}
+}
+
+func TestSchemaCache_PanickingSubscriber(t *testing.T) {
+	fake, cache := newSchemaTestCache(t, oc.SchemaCacheOptions{})
+	ctx := context.Background()
+
+	var checksums []string
+
+	cache.Subscribe(func(change oc.SchemaChange) {
+		if change.Checksum == "v1" {
+			panic("subscriber failed")
+		}
+
+		checksums = append(checksums, change.Checksum)
+	})
+
+	func() {
+		defer func() {
+			if r := recover(); r != "subscriber failed" {
+				t.Errorf("expected the subscriber panic, got %v", r)
+			}
+		}()
+
+		_, _ = cache.Schema(ctx)
+	}()
+
+	fake.m.Lock()
+	fake.ConfigChecksum = "v2"
+	fake.m.Unlock()
+
+	if _, err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("failed to refresh schema: %v", err)
+	}
+
+	if len(checksums) != 1 || checksums[0] != "v2" {
+		t.Errorf("expected changes to be delivered after a panic, got %v", checksums)
+	}
+}
+
+func TestSchemaCache_RefreshInterval(t *testing.T) {
+	fake, cache := newSchemaTestCache(t, oc.SchemaCacheOptions{
+		RefreshInterval: time.Nanosecond,
+	})
+	ctx := context.Background()
+
+	if _, err := cache.Schema(ctx); err != nil {
+		t.Fatalf("failed to get schema: %v", err)
+	}
+
+	reloaded, err := cache.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check schema: %v", err)
+	}
+
+	if !reloaded || fake.SchemaLoads != 2 {
+		t.Errorf("expected a stale schema to be reloaded, got %d loads", fake.SchemaLoads)
+	}
+}
+
+func TestSchemaCache_Run(t *testing.T) {
+	fake, cache := newSchemaTestCache(t, oc.SchemaCacheOptions{
+		CheckInterval: time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan string, 10)
+
+	cache.Subscribe(func(change oc.SchemaChange) {
+		changed <- change.Checksum
+	})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- cache.Run(ctx)
+	}()
+
+	if got := <-changed; got != "v1" {
+		t.Fatalf("expected initial load, got checksum %q", got)
+	}
+
+	fake.m.Lock()
+	fake.ConfigChecksum = "v2"
+	fake.m.Unlock()
+
+	select {
+	case got := <-changed:
+		if got != "v2" {
+			t.Errorf("expected checksum v2, got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the schema change")
+	}
+
+	cancel()
+
+	if err := <-done; err != context.Canceled { //nolint:errorlint
+		t.Errorf("expected Run to stop with context.Canceled, got %v", err)
+	}
+}
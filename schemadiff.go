@@ -0,0 +1,175 @@
+package oc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Schema attributes reported by SchemaDifference.
+const (
+	SchemaAttrType           = "type"
+	SchemaAttrMultiValued    = "multiValued"
+	SchemaAttrSearchable     = "searchable"
+	SchemaAttrSuggest        = "suggest"
+	SchemaAttrIndexFieldType = "indexFieldType"
+)
+
+// SchemaDifference is a difference between two schemas. Property is
+// empty for added and removed content types, and Attribute is only
+// set for changed properties.
+type SchemaDifference struct {
+	Type        ChangeType
+	ContentType string
+	Property    string
+	Attribute   string
+	Old         string
+	New         string
+	// Breaking is set for differences that can break existing
+	// queries or struct mappings.
+	Breaking bool
+}
+
+func (sd SchemaDifference) String() string {
+	name := sd.ContentType
+	if sd.Property != "" {
+		name += "." + sd.Property
+	}
+
+	var s string
+
+	if sd.Type == ChangeChanged {
+		s = fmt.Sprintf("%s: %s changed from %q to %q", name, sd.Attribute, sd.Old, sd.New)
+	} else {
+		s = fmt.Sprintf("%s: %s", name, sd.Type)
+	}
+
+	if sd.Breaking {
+		s += " (breaking)"
+	}
+
+	return s
+}
+
+// SchemaDiff compares the active schema with a new one, usually the
+// temporary configuration, and returns the differences sorted by
+// content type and property.
+//
+// Removed content types and properties, and changes of the property
+// type or multi-valued flag are breaking. So are changes of the
+// index field type, and properties that stop being searchable or
+// available for suggestions.
+func SchemaDiff(active, temp *ContentTypesResponse) []SchemaDifference {
+	var diffs []SchemaDifference
+
+	for _, ct := range active.ContentTypes {
+		if temp.Type(ct.Name) == nil {
+			diffs = append(diffs, SchemaDifference{
+				Type:        ChangeRemoved,
+				ContentType: ct.Name,
+				Breaking:    true,
+			})
+		}
+	}
+
+	for _, newType := range temp.ContentTypes {
+		oldType := active.Type(newType.Name)
+		if oldType == nil {
+			diffs = append(diffs, SchemaDifference{
+				Type:        ChangeAdded,
+				ContentType: newType.Name,
+			})
+
+			continue
+		}
+
+		diffs = append(diffs, diffContentType(oldType, &newType)...)
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].ContentType != diffs[j].ContentType {
+			return diffs[i].ContentType < diffs[j].ContentType
+		}
+
+		return diffs[i].Property < diffs[j].Property
+	})
+
+	return diffs
+}
+
+func diffContentType(a, b *ContentType) []SchemaDifference {
+	var diffs []SchemaDifference
+
+	for _, p := range a.Properties {
+		if b.Property(p.Name) == nil {
+			diffs = append(diffs, SchemaDifference{
+				Type:        ChangeRemoved,
+				ContentType: a.Name,
+				Property:    p.Name,
+				Breaking:    true,
+			})
+		}
+	}
+
+	for _, newProp := range b.Properties {
+		oldProp := a.Property(newProp.Name)
+		if oldProp == nil {
+			diffs = append(diffs, SchemaDifference{
+				Type:        ChangeAdded,
+				ContentType: a.Name,
+				Property:    newProp.Name,
+			})
+
+			continue
+		}
+
+		changed := func(attr string, oldValue, newValue string, breaking bool) {
+			if oldValue == newValue {
+				return
+			}
+
+			diffs = append(diffs, SchemaDifference{
+				Type:        ChangeChanged,
+				ContentType: a.Name,
+				Property:    newProp.Name,
+				Attribute:   attr,
+				Old:         oldValue,
+				New:         newValue,
+				Breaking:    breaking,
+			})
+		}
+
+		changed(SchemaAttrType, oldProp.TypeName(), newProp.TypeName(), true)
+		changed(SchemaAttrMultiValued,
+			strconv.FormatBool(oldProp.MultiValued),
+			strconv.FormatBool(newProp.MultiValued), true)
+		changed(SchemaAttrSearchable,
+			strconv.FormatBool(oldProp.Searchable),
+			strconv.FormatBool(newProp.Searchable), oldProp.Searchable)
+		changed(SchemaAttrSuggest,
+			strconv.FormatBool(oldProp.Suggest),
+			strconv.FormatBool(newProp.Suggest), oldProp.Suggest)
+		changed(SchemaAttrIndexFieldType,
+			oldProp.IndexFieldTypeName(), newProp.IndexFieldTypeName(),
+			oldProp.IndexFieldType != IndexFieldNone)
+	}
+
+	return diffs
+}
+
+// DiffTemporarySchema compares the active schema with the schema of
+// the temporary configuration.
+func (c *Client) DiffTemporarySchema(ctx context.Context) ([]SchemaDifference, error) {
+	active, err := c.ContentTypes(ctx, ContentTypesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active schema: %w", err)
+	}
+
+	temp, err := c.ContentTypes(ctx, ContentTypesRequest{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load temporary schema: %w", err)
+	}
+
+	return SchemaDiff(active, temp), nil
+}
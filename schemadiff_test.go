@@ -0,0 +1,77 @@
+package oc_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	active := oc.ContentTypesResponse{
+		ContentTypes: []oc.ContentType{
+			{
+				Name: "Article",
+				Properties: []oc.PropertyDefinition{
+					{Name: "Headline", Type: oc.PropertyTypeString, Searchable: true, IndexFieldType: oc.IndexFieldText},
+					{Name: "Pages", Type: oc.PropertyTypeInteger, IndexFieldType: oc.IndexFieldInteger},
+					{Name: "Section", Type: oc.PropertyTypeString, Suggest: true, IndexFieldType: oc.IndexFieldString},
+					{Name: "Authors", Type: oc.PropertyTypeRelation, Relation: "Concept", MultiValued: true},
+					{Name: "Legacy", Type: oc.PropertyTypeString},
+				},
+			},
+			{Name: "Graphic"},
+		},
+	}
+
+	temp := oc.ContentTypesResponse{
+		ContentTypes: []oc.ContentType{
+			{
+				Name: "Article",
+				Properties: []oc.PropertyDefinition{
+					{Name: "Headline", Type: oc.PropertyTypeString, IndexFieldType: oc.IndexFieldText},
+					{Name: "Pages", Type: oc.PropertyTypeString, Searchable: true, IndexFieldType: oc.IndexFieldInteger},
+					{Name: "Section", Type: oc.PropertyTypeString, Suggest: true, IndexFieldType: oc.IndexFieldStringLowercase},
+					{Name: "Authors", Type: oc.PropertyTypeRelation, Relation: "Author", MultiValued: true},
+					{Name: "Byline", Type: oc.PropertyTypeString},
+				},
+			},
+			{Name: "Event"},
+		},
+	}
+
+	want := []oc.SchemaDifference{
+		{Type: oc.ChangeChanged, ContentType: "Article", Property: "Authors",
+			Attribute: oc.SchemaAttrType, Old: "Concept", New: "Author", Breaking: true},
+		{Type: oc.ChangeAdded, ContentType: "Article", Property: "Byline"},
+		{Type: oc.ChangeChanged, ContentType: "Article", Property: "Headline",
+			Attribute: oc.SchemaAttrSearchable, Old: "true", New: "false", Breaking: true},
+		{Type: oc.ChangeRemoved, ContentType: "Article", Property: "Legacy", Breaking: true},
+		{Type: oc.ChangeChanged, ContentType: "Article", Property: "Pages",
+			Attribute: oc.SchemaAttrType, Old: "INTEGER", New: "STRING", Breaking: true},
+		{Type: oc.ChangeChanged, ContentType: "Article", Property: "Pages",
+			Attribute: oc.SchemaAttrSearchable, Old: "false", New: "true"},
+		{Type: oc.ChangeChanged, ContentType: "Article", Property: "Section",
+			Attribute: oc.SchemaAttrIndexFieldType, Old: "STRING", New: "STRING_LOWERCASE", Breaking: true},
+		{Type: oc.ChangeAdded, ContentType: "Event"},
+		{Type: oc.ChangeRemoved, ContentType: "Graphic", Breaking: true},
+	}
+
+	got := oc.SchemaDiff(&active, &temp)
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SchemaDiff() mismatch (-want +got):\n%s", diff)
+	}
+
+	if s := got[0].String(); s != `Article.Authors: type changed from "Concept" to "Author" (breaking)` {
+		t.Errorf("unexpected description %q", s)
+	}
+
+	if s := got[7].String(); s != "Event: added" {
+		t.Errorf("unexpected description %q", s)
+	}
+
+	if diffs := oc.SchemaDiff(&active, &active); len(diffs) != 0 {
+		t.Errorf("expected no differences for identical schemas, got %v", diffs)
+	}
+}
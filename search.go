@@ -53,14 +53,22 @@ type SearchRequest struct {
 	Deleted     bool
 	Facets      SearchFacets
 	Highlight   []string
-	Created     DateRange
-	Updated     DateRange
+	// HighlightOptions controls the fragments returned for the
+	// Highlight fields.
+	HighlightOptions HighlightOptions
+	Created          DateRange
+	Updated          DateRange
 }
 
 type SearchFacets struct {
 	Fields   []string
 	Limit    int
 	MinCount int
+	// FieldFacets are field facets with per field options, they're
+	// requested together with Fields.
+	FieldFacets []FieldFacet
+	DateFacets  []DateFacet
+	Queries     []QueryFacet
 }
 
 type SearchSort struct {
@@ -85,6 +93,9 @@ type Hit struct {
 	ID         string     `json:"id"`
 	Version    int        `json:"version"`
 	Properties Properties `json:"properties"`
+	// Highlights are the highlighted fragments of the hit, joined
+	// from SearchResponse.Highlight.
+	Highlights Highlights `json:"-"`
 }
 
 func (h *Hit) UnmarshalJSON(data []byte) error {
@@ -190,25 +201,31 @@ func (p Properties) Relationships(property string) ([]Properties, bool) {
 			continue
 		}
 
-		relProps := make(Properties)
+		v[i] = relationshipProperties(m)
+	}
+
+	return v, true
+}
 
-		for k := range m {
-			relValues, ok := m[k].([]interface{})
-			if !ok {
-				continue
-			}
+func relationshipProperties(m map[string]interface{}) Properties {
+	relProps := make(Properties)
 
-			relProps[k] = relValues
+	for k := range m {
+		relValues, ok := m[k].([]interface{})
+		if !ok {
+			continue
 		}
 
-		v[i] = relProps
+		relProps[k] = relValues
 	}
 
-	return v, true
+	return relProps
 }
 
 type FacetFields struct {
-	Fields []FacetField `json:"fields"`
+	Fields     []FacetField       `json:"fields"`
+	DateFields []DateFacetField   `json:"dateFields,omitempty"`
+	Queries    []QueryFacetResult `json:"queries,omitempty"`
 }
 
 type FacetField struct {
@@ -224,13 +241,6 @@ type Frequency struct {
 	Frequency int    `json:"frequency"`
 }
 
-type Stats struct {
-	Duration int         `json:"duration"`
-	Hits     interface{} `json:"hits"`
-}
-
-type Highlights map[string][]string
-
 func (sr *SearchRequest) QueryValues() (url.Values, error) {
 	q := url.Values{}
 
@@ -320,20 +330,12 @@ func (sr *SearchRequest) QueryValues() (url.Values, error) {
 		q.Add(paramName, sr.Updated.End.Date.Format("2006-01-02T15:04:05Z"))
 	}
 
-	if len(sr.Facets.Fields) > 0 {
-		q.Set("facet.indexfield", strings.Join(sr.Facets.Fields, "\n"))
-
-		if sr.Facets.Limit > 0 {
-			q.Set("facet.limit", strconv.Itoa(sr.Facets.Limit))
-		}
-
-		if sr.Facets.MinCount > 0 {
-			q.Set("facet.mincount", strconv.Itoa(sr.Facets.MinCount))
-		}
-	}
+	sr.Facets.addQueryValues(q)
 
 	if len(sr.Highlight) > 0 {
 		q.Set("highlight.indexfield", strings.Join(sr.Highlight, "\n"))
+
+		sr.HighlightOptions.addQueryValues(q)
 	}
 
 	return q, nil
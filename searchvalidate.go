@@ -0,0 +1,200 @@
+package oc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Validate checks the content type, sort, facet and highlight index
+// fields, and the requested properties, against the schema. If the
+// request has no content type the fields are looked up in all
+// content types. A *ValidationError listing every problem is returned
+// if the request has problems.
+func (sr *SearchRequest) Validate(schema *ContentTypesResponse) error {
+	verr := ValidationError{subject: "search request"}
+
+	types := schema.ContentTypes
+
+	if sr.ContentType != "" {
+		ct := schema.Type(sr.ContentType)
+		if ct == nil {
+			verr.add("ContentType", "unknown content type %q", sr.ContentType)
+
+			return &verr
+		}
+
+		types = []ContentType{*ct}
+	}
+
+	for _, s := range sr.Sort {
+		defs := indexFieldDefinitions(types, s.IndexField, &verr, "Sort")
+
+		if len(defs) > 0 && !anyDefinition(defs, isSortable) {
+			verr.add("Sort", "%q can't be used for sorting, only single-valued fields that aren't tokenized can",
+				s.IndexField)
+		}
+	}
+
+	for _, field := range sr.Facets.Fields {
+		indexFieldDefinitions(types, field, &verr, "Facets.Fields")
+	}
+
+	for _, f := range sr.Facets.FieldFacets {
+		indexFieldDefinitions(types, f.Field, &verr, "Facets.FieldFacets")
+	}
+
+	for _, f := range sr.Facets.DateFacets {
+		defs := indexFieldDefinitions(types, f.Field, &verr, "Facets.DateFacets")
+
+		if len(defs) > 0 && !anyDefinition(defs, isDate) {
+			verr.add("Facets.DateFacets", "%q isn't a date field", f.Field)
+		}
+
+		if !f.Start.IsZero() && !f.End.IsZero() && f.End.Before(f.Start) {
+			verr.add("Facets.DateFacets", "the range of %q ends before it starts", f.Field)
+		}
+	}
+
+	for _, field := range sr.Highlight {
+		indexFieldDefinitions(types, field, &verr, "Highlight")
+	}
+
+	for _, p := range []struct {
+		Field string
+		Value string
+	}{
+		{"Property", sr.Property},
+		{"Properties", sr.Properties},
+	} {
+		if p.Value == "" {
+			continue
+		}
+
+		var pl PropertyList
+
+		if err := pl.UnmarshalText([]byte(p.Value)); err != nil {
+			verr.add(p.Field, "invalid property list: %v", err)
+
+			continue
+		}
+
+		validatePropertyList(schema, types, pl, "", &verr, p.Field)
+	}
+
+	if len(verr.Problems) > 0 {
+		return &verr
+	}
+
+	return nil
+}
+
+// ValidateSearch validates a search request against the cached
+// schema, see SearchRequest.Validate. Call it before Search to catch
+// problems without a round trip to OC.
+func (sc *SchemaCache) ValidateSearch(ctx context.Context, req *SearchRequest) error {
+	schema, err := sc.Schema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load schema for validation: %w", err)
+	}
+
+	return req.Validate(schema)
+}
+
+func isSortable(p PropertyDefinition) bool {
+	return !p.MultiValued && p.IndexFieldType != IndexFieldText
+}
+
+func isDate(p PropertyDefinition) bool {
+	return p.IndexFieldType == IndexFieldDate
+}
+
+func anyDefinition(defs []PropertyDefinition, fn func(p PropertyDefinition) bool) bool {
+	for _, p := range defs {
+		if fn(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexFieldDefinitions returns the definitions of an indexed field
+// in the given content types, and reports unknown and unindexed
+// fields.
+func indexFieldDefinitions(
+	types []ContentType, name string, verr *ValidationError, field string,
+) []PropertyDefinition {
+	var (
+		defs  []PropertyDefinition
+		found bool
+	)
+
+	for i := range types {
+		p := types[i].Property(name)
+		if p == nil {
+			continue
+		}
+
+		found = true
+
+		if p.IndexFieldType != IndexFieldNone {
+			defs = append(defs, *p)
+		}
+	}
+
+	switch {
+	case !found:
+		verr.add(field, "unknown index field %q", name)
+	case len(defs) == 0:
+		verr.add(field, "%q isn't indexed", name)
+	}
+
+	return defs
+}
+
+// validatePropertyList checks that the properties in a property list
+// exist in at least one of the content types, and that nested
+// properties exist in the related content types.
+func validatePropertyList(
+	schema *ContentTypesResponse, types []ContentType,
+	pl PropertyList, prefix string, verr *ValidationError, field string,
+) {
+	for _, ref := range pl {
+		var (
+			found    bool
+			relation bool
+			related  []ContentType
+		)
+
+		for i := range types {
+			p := types[i].Property(ref.Name)
+			if p == nil {
+				continue
+			}
+
+			found = true
+
+			if p.Type != PropertyTypeRelation {
+				continue
+			}
+
+			relation = true
+
+			if ct := schema.Type(p.Relation); ct != nil {
+				related = append(related, *ct)
+			}
+		}
+
+		path := prefix + ref.Name
+
+		switch {
+		case !found:
+			verr.add(field, "unknown property %q", path)
+		case len(ref.Nested) == 0:
+		case !relation:
+			verr.add(field, "%q isn't a relationship and can't have nested properties", path)
+		case len(related) > 0:
+			validatePropertyList(schema, related, ref.Nested, path+".", verr, field)
+		}
+	}
+}
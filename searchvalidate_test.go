@@ -0,0 +1,118 @@
+package oc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func TestSearchRequest_Validate(t *testing.T) {
+	var schema oc.ContentTypesResponse
+
+	loadTestData(t, "contenttypesresponse.json", &schema)
+
+	valid := oc.SearchRequest{
+		ContentType: "Article",
+		Sort:        []oc.SearchSort{{IndexField: "updated", Descending: true}},
+		Facets:      oc.SearchFacets{Fields: []string{"ArticleMetaNewsValue"}},
+		Highlight:   []string{"ArticleBody"},
+		Properties:  "uuid,ArticleBody,ConceptRelations[uuid,ConceptAssociatedWithType]",
+	}
+
+	if err := valid.Validate(&schema); err != nil {
+		t.Errorf("expected a valid request, got: %v", err)
+	}
+
+	invalid := oc.SearchRequest{
+		ContentType: "Article",
+		Sort: []oc.SearchSort{
+			{IndexField: "updatd"},
+			{IndexField: "ArticleBody"},
+			{IndexField: "group"},
+		},
+		Facets: oc.SearchFacets{
+			Fields:     []string{"ConceptRelations"},
+			DateFacets: []oc.DateFacet{{Field: "uuid"}},
+		},
+		Highlight:  []string{"Headline"},
+		Properties: "uuid,Headlin,uuid[version],ConceptRelations[Nope]",
+	}
+
+	err := invalid.Validate(&schema)
+
+	var verr *oc.ValidationError
+
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got: %v", err)
+	}
+
+	want := []oc.ValidationProblem{
+		{Field: "Sort", Message: `unknown index field "updatd"`},
+		{Field: "Sort", Message: `"ArticleBody" can't be used for sorting, only single-valued fields that aren't tokenized can`},
+		{Field: "Sort", Message: `"group" can't be used for sorting, only single-valued fields that aren't tokenized can`},
+		{Field: "Facets.Fields", Message: `"ConceptRelations" isn't indexed`},
+		{Field: "Facets.DateFacets", Message: `"uuid" isn't a date field`},
+		{Field: "Highlight", Message: `unknown index field "Headline"`},
+		{Field: "Properties", Message: `unknown property "Headlin"`},
+		{Field: "Properties", Message: `"uuid" isn't a relationship and can't have nested properties`},
+		{Field: "Properties", Message: `unknown property "ConceptRelations.Nope"`},
+	}
+
+	if diff := cmp.Diff(want, verr.Problems); diff != "" {
+		t.Errorf("Validate() problems mismatch (-want +got):\n%s", diff)
+	}
+
+	unknownType := oc.SearchRequest{ContentType: "Articel"}
+
+	err = unknownType.Validate(&schema)
+	if err == nil || err.Error() != `invalid search request: ContentType: unknown content type "Articel"` {
+		t.Errorf("unexpected error for an unknown content type: %v", err)
+	}
+
+	anyType := oc.SearchRequest{Properties: "ConceptAssociatedWithType,ArticleBody"}
+
+	if err := anyType.Validate(&schema); err != nil {
+		t.Errorf("expected properties of any content type to be accepted, got: %v", err)
+	}
+}
+
+func TestSchemaCache_ValidateSearch(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	var schema oc.ContentTypesResponse
+
+	loadTestData(t, "contenttypesresponse.json", &schema)
+
+	fake.Schema = &schema
+
+	cache, err := oc.NewSchemaCache(client, oc.SchemaCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cache.ValidateSearch(context.Background(), &oc.SearchRequest{
+		ContentType: "Article",
+		Sort:        []oc.SearchSort{{IndexField: "updatd"}},
+	})
+
+	var verr *oc.ValidationError
+
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got: %v", err)
+	}
+
+	if fake.Requests != 2 {
+		t.Errorf("expected only the health and schema requests, got %d requests", fake.Requests)
+	}
+
+	err = cache.ValidateSearch(context.Background(), &oc.SearchRequest{
+		ContentType: "Article",
+		Sort:        []oc.SearchSort{{IndexField: "updated"}},
+	})
+	if err != nil {
+		t.Errorf("expected a valid request, got: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package oc
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Stats are the statistics of a search.
+type Stats struct {
+	// Duration is the time the search took in milliseconds.
+	Duration int `json:"duration"`
+	// Hits are statistics about the values of index fields in the
+	// hits, keyed by field. OC only includes them on request, and
+	// they're left empty if OC returns them in an unknown format.
+	Hits map[string]FieldStats `json:"hits"`
+}
+
+// Elapsed returns the duration of the search.
+func (s Stats) Elapsed() time.Duration {
+	return time.Duration(s.Duration) * time.Millisecond
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Stats) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Duration int             `json:"duration"`
+		Hits     json.RawMessage `json:"hits"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	*s = Stats{Duration: raw.Duration}
+
+	var hits map[string]FieldStats
+
+	// Unknown statistics shouldn't fail the whole search.
+	if err := json.Unmarshal(raw.Hits, &hits); err == nil {
+		s.Hits = hits
+	}
+
+	return nil
+}
+
+// FieldStats are statistics about the values of an index field. Min
+// and Max are kept as text as they can be numbers or dates.
+type FieldStats struct {
+	Min     string
+	Max     string
+	Count   int64
+	Missing int64
+	Sum     float64
+	Mean    float64
+	StdDev  float64
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (fs *FieldStats) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Min     json.RawMessage `json:"min"`
+		Max     json.RawMessage `json:"max"`
+		Count   int64           `json:"count"`
+		Missing int64           `json:"missing"`
+		Sum     float64         `json:"sum"`
+		Mean    float64         `json:"mean"`
+		StdDev  float64         `json:"stddev"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	*fs = FieldStats{
+		Min:     rawText(raw.Min),
+		Max:     rawText(raw.Max),
+		Count:   raw.Count,
+		Missing: raw.Missing,
+		Sum:     raw.Sum,
+		Mean:    raw.Mean,
+		StdDev:  raw.StdDev,
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (fs FieldStats) MarshalJSON() ([]byte, error) {
+	value := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+
+		return s
+	}
+
+	return json.Marshal(map[string]interface{}{ //nolint:wrapcheck
+		"min":     value(fs.Min),
+		"max":     value(fs.Max),
+		"count":   fs.Count,
+		"missing": fs.Missing,
+		"sum":     fs.Sum,
+		"mean":    fs.Mean,
+		"stddev":  fs.StdDev,
+	})
+}
+
+// rawText returns a JSON string as its value and other JSON values,
+// like numbers, as their text.
+func rawText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var s string
+
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	return string(raw)
+}
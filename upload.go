@@ -3,6 +3,7 @@ package oc
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -11,6 +12,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type FileSet map[string]File
@@ -52,6 +55,53 @@ type UploadRequest struct {
 	Unit    string
 	Batch   bool
 	IfMatch string
+
+	// UUIDKey is the key of the object in the source system. If UUID
+	// isn't set the object UUID is derived from the key, see
+	// ObjectUUID.
+	UUIDKey string
+	// UUIDNamespace is the namespace used to derive the UUID from
+	// UUIDKey. Defaults to the SourceNamespace of Source.
+	UUIDNamespace uuid.UUID
+
+	// Validation enables pre-flight validation of the request, see
+	// ValidateUpload.
+	Validation *UploadValidation
+
+	// Progress is called as file data is sent to OC.
+	Progress func(UploadProgress)
+	// ContentLength makes the upload send a Content-Length header
+	// instead of using chunked transfer encoding. The size of every
+	// file reader must then be known up front, which is the case for
+	// readers with a Len method, like bytes.Reader, and for seekable
+	// readers like os.File.
+	ContentLength bool
+}
+
+// UploadProgress describes the progress of an upload.
+type UploadProgress struct {
+	// Field is the form field of the file that is being sent.
+	Field string
+	// FileSent is the number of bytes of the file that have been
+	// sent so far.
+	FileSent int64
+	// FileSize is the size of the file, or -1 if it's unknown.
+	FileSize int64
+	// Sent is the number of bytes sent so far for all files.
+	Sent int64
+	// Total is the size of all files, or -1 if it's unknown.
+	Total int64
+	// Elapsed is the time since the upload started.
+	Elapsed time.Duration
+}
+
+// Throughput returns the average upload speed in bytes per second.
+func (p UploadProgress) Throughput() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Sent) / p.Elapsed.Seconds()
 }
 
 type UploadResponse struct {
@@ -61,150 +111,404 @@ type UploadResponse struct {
 }
 
 // Upload saves the fileset in the OC database.
-func (c *Client) Upload(ctx context.Context, req UploadRequest) (*UploadResponse, error) { //nolint:gocognit
+//
+// The request body is written by a separate goroutine that is tied
+// to the lifetime of the request: if the request fails, or ctx is
+// cancelled, the body is aborted, and if writing the body fails the
+// request is aborted. Upload doesn't return until the body writer has
+// stopped, unless ctx is cancelled while the writer is blocked
+// reading from a File.Reader, in which case the writer exits as soon
+// as that read returns.
+func (c *Client) Upload(ctx context.Context, req UploadRequest) (*UploadResponse, error) {
 	start := time.Now()
-	url := c.url("objectupload", nil)
-	errChan := make(chan error)
-	done := make(chan bool)
+
+	req.UUID = req.objectUUID()
+
+	if req.Validation != nil {
+		files, err := c.validateUpload(ctx, req, *req.Validation)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Files = files
+	}
+
+	if c.metrics != nil {
+		defer func() {
+			duration := time.Since(start)
+			c.metrics.addDuration(ctx, "objectupload", float64(duration.Milliseconds()))
+		}()
+	}
+
 	pipeOut, pipeIn := io.Pipe()
 	writer := multipart.NewWriter(pipeIn)
 
-	var res UploadResponse
+	sizes, total := fileSizes(req.Files)
+
+	contentLength := int64(-1)
+
+	if req.ContentLength {
+		n, err := uploadBodySize(req, sizes, writer.Boundary())
+		if err != nil {
+			return nil, err
+		}
+
+		contentLength = n
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url("objectupload", nil), pipeOut)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if contentLength >= 0 {
+		r.ContentLength = contentLength
+	}
+
+	if req.Unit != "" {
+		r.Header.Set("X-Imid-Unit", req.Unit)
+	}
+
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+
+	if req.IfMatch != "" {
+		r.Header.Set("If-Match", req.IfMatch)
+	}
+
+	if c.auth != nil {
+		c.auth(r)
+	}
+
+	progress := uploadProgress{
+		fn:    req.Progress,
+		start: start,
+		total: total,
+	}
+
+	// Buffered so that the writer never blocks on reporting its
+	// result, even if we've stopped waiting for it.
+	writeErr := make(chan error, 1)
 
 	go func() {
-		defer func(writer *multipart.Writer) {
-			_ = writer.Close()
-		}(writer)
-		defer func(pipeIn *io.PipeWriter) {
-			_ = pipeIn.Close()
-		}(pipeIn)
+		err := writeUploadBody(writer, req, sizes, &progress)
+
+		// Closing with a nil error is the same as Close(), and
+		// otherwise the request fails with the write error.
+		_ = pipeIn.CloseWithError(err)
+
+		writeErr <- err
+	}()
+
+	// The transport can be stuck reading the body when the context
+	// is cancelled, unblock it by failing the read.
+	stopCancel := context.AfterFunc(ctx, func() {
+		_ = pipeOut.CloseWithError(ctx.Err())
+	})
 
-		fields := mimeFields{
-			"source": req.Source,
-			"batch":  strconv.FormatBool(req.Batch),
+	resp, err := c.httpClient.Do(r)
+
+	stopCancel()
+
+	// Stops the body writer if the request ended before the body was
+	// consumed, a no-op if it already has finished.
+	_ = pipeOut.CloseWithError(errUploadFinished)
+
+	bodyErr := waitForWriter(ctx, writeErr)
+
+	if err != nil {
+		// The writer error is the root cause if the request failed
+		// because the body couldn't be produced.
+		if bodyErr != nil {
+			return nil, fmt.Errorf("failed to write upload body: %w", bodyErr)
 		}
 
-		if req.UUID != "" {
-			fields["id"] = req.UUID
+		// The transport may report the aborted body rather than the
+		// cancellation.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("failed to perform upload request: %w", ctxErr)
 		}
 
-		for field, file := range req.Files {
-			fields[field] = file.Name
-			fields[field+"-mimetype"] = file.Mimetype
+		return nil, fmt.Errorf("failed to perform upload request: %w", err)
+	}
+
+	defer safeClose(c.logger, "upload response", resp.Body)
+
+	if c.metrics != nil {
+		c.metrics.incStatusCode(ctx, "objectupload", resp.StatusCode)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newResponseError(resp)
+	}
+
+	res := UploadResponse{
+		ETag: resp.Header.Get("Etag"),
+	}
+
+	uuidBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Logf("failed to read response UUID for upload: %v", err)
+	}
+
+	v, err := objectVersionFromHeader(resp.Header, versionOptional)
+	if err != nil {
+		c.logger.Logf("failed to parse existing version: %v", err)
+	}
+
+	res.Version = v
+	res.UUID = string(bytes.TrimSpace(uuidBytes))
+
+	return &res, nil
+}
+
+// errUploadFinished is used to stop the body writer once the upload
+// request has completed.
+var errUploadFinished = errors.New("the upload request has finished")
+
+// waitForWriter waits for the body writer to stop and returns its
+// error. Errors caused by the request ending are ignored.
+func waitForWriter(ctx context.Context, writeErr <-chan error) error {
+	var err error
+
+	select {
+	case err = <-writeErr:
+	case <-ctx.Done():
+		return nil
+	}
+
+	if errors.Is(err, errUploadFinished) || (err != nil && errors.Is(err, ctx.Err())) {
+		return nil
+	}
+
+	return err
+}
+
+func writeUploadBody(
+	writer *multipart.Writer, req UploadRequest,
+	sizes map[string]int64, progress *uploadProgress,
+) error {
+	err := uploadFields(req).write(writer)
+	if err != nil {
+		return err
+	}
+
+	for field, file := range req.Files {
+		if file.Reader == nil {
+			continue
 		}
 
-		err := fields.write(writer)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		for _, file := range req.Files {
-			if file.Reader == nil {
-				continue
-			}
-
-			header := make(textproto.MIMEHeader)
-			header.Set(
-				"Content-Disposition",
-				fmt.Sprintf(
-					`form-data; name="%s"; filename="%s"`,
-					escapeQuotes(file.Name),
-					escapeQuotes(file.Name),
-				))
-			header.Set("Content-Type", file.Mimetype)
-
-			part, err := writer.CreatePart(header)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			_, err = io.Copy(part, file.Reader)
-			if err != nil {
-				errChan <- err
-				return
-			}
-		}
-
-		err = writer.Close()
+		part, err := writer.CreatePart(filePartHeader(file))
 		if err != nil {
-			return
+			return fmt.Errorf("failed to create part for %q: %w", field, err)
 		}
-	}()
 
-	go func() {
-		r, err := http.NewRequest("POST", url, pipeOut)
+		size, ok := sizes[field]
+		if !ok {
+			size = -1
+		}
+
+		_, err = io.Copy(part, progress.reader(field, size, file.Reader))
 		if err != nil {
-			errChan <- err
-			return
+			return fmt.Errorf("failed to write %q: %w", field, err)
 		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+
+	return nil
+}
 
-		r = r.WithContext(ctx)
+func uploadFields(req UploadRequest) mimeFields {
+	fields := mimeFields{
+		"source": req.Source,
+		"batch":  strconv.FormatBool(req.Batch),
+	}
+
+	if req.UUID != "" {
+		fields["id"] = req.UUID
+	}
+
+	for field, file := range req.Files {
+		fields[field] = file.Name
+		fields[field+"-mimetype"] = file.Mimetype
+	}
+
+	return fields
+}
+
+func filePartHeader(file File) textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+
+	header.Set(
+		"Content-Disposition",
+		fmt.Sprintf(
+			`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(file.Name),
+			escapeQuotes(file.Name),
+		))
+	header.Set("Content-Type", file.Mimetype)
 
-		if req.Unit != "" {
-			r.Header.Set("X-Imid-Unit", req.Unit)
+	return header
+}
+
+// fileSizes returns the sizes of the files that have a known size,
+// and their total size, or -1 if any size is unknown.
+func fileSizes(files FileSet) (map[string]int64, int64) {
+	sizes := make(map[string]int64, len(files))
+
+	var total int64
+
+	for field, file := range files {
+		if file.Reader == nil {
+			continue
 		}
 
-		r.Header.Set("Content-Type", writer.FormDataContentType())
+		size, ok := readerSize(file.Reader)
+		if !ok {
+			total = -1
 
-		if req.IfMatch != "" {
-			r.Header.Set("If-Match", req.IfMatch)
+			continue
 		}
 
-		if c.auth != nil {
-			c.auth(r)
+		sizes[field] = size
+
+		if total >= 0 {
+			total += size
 		}
+	}
+
+	return sizes, total
+}
 
-		resp, err := c.httpClient.Do(r)
+// readerSize returns the number of bytes left to read from r, if it
+// can be determined without reading.
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
 		if err != nil {
-			errChan <- err
-			return
+			return 0, false
 		}
 
-		defer safeClose(c.logger, "upload response", resp.Body)
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
 
-		if c.metrics != nil {
-			c.metrics.incStatusCode(ctx, "objectupload", resp.StatusCode)
+		if _, err := v.Seek(current, io.SeekStart); err != nil {
+			return 0, false
 		}
 
-		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-			res.ETag = resp.Header.Get("Etag")
+		return end - current, true
+	default:
+		return 0, false
+	}
+}
 
-			uuidBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				c.logger.Logf("failed to read response UUID for upload: %w", err)
-			}
+// uploadBodySize calculates the size of the multipart body by writing
+// the form without the file contents.
+func uploadBodySize(req UploadRequest, sizes map[string]int64, boundary string) (int64, error) {
+	var counter countingWriter
 
-			v, err := objectVersionFromHeader(resp.Header, versionOptional)
-			if err != nil {
-				c.logger.Logf("failed to parse existing version: %w", err)
-			}
+	writer := multipart.NewWriter(&counter)
 
-			res.Version = v
+	err := writer.SetBoundary(boundary)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set boundary: %w", err)
+	}
 
-			res.UUID = string(bytes.TrimSpace(uuidBytes))
+	err = uploadFields(req).write(writer)
+	if err != nil {
+		return 0, err
+	}
 
-			done <- true
-		} else {
-			errChan <- newResponseError(resp)
+	for field, file := range req.Files {
+		if file.Reader == nil {
+			continue
 		}
-	}()
 
-	select {
-	case err := <-errChan:
-		if c.metrics != nil {
-			duration := time.Since(start)
-			c.metrics.addDuration(ctx, "objectupload", float64(duration.Milliseconds()))
+		size, ok := sizes[field]
+		if !ok {
+			return 0, fmt.Errorf(
+				"can't calculate the content length, the size of %q is unknown", field)
 		}
 
-		return nil, err
-	case <-done:
-		if c.metrics != nil {
-			duration := time.Since(start)
-			c.metrics.addDuration(ctx, "objectupload", float64(duration.Milliseconds()))
+		_, err := writer.CreatePart(filePartHeader(file))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create part for %q: %w", field, err)
 		}
 
-		return &res, nil
+		counter.n += size
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	return counter.n, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.n += int64(len(p))
+
+	return len(p), nil
+}
+
+// uploadProgress keeps track of the bytes sent in an upload. Files
+// are sent one at a time, so no locking is needed.
+type uploadProgress struct {
+	fn    func(UploadProgress)
+	start time.Time
+	total int64
+	sent  int64
+}
+
+func (p *uploadProgress) reader(field string, size int64, r io.Reader) io.Reader {
+	if p.fn == nil {
+		return r
 	}
+
+	return &progressReader{
+		progress: p,
+		field:    field,
+		size:     size,
+		r:        r,
+	}
+}
+
+type progressReader struct {
+	progress *uploadProgress
+	field    string
+	size     int64
+	sent     int64
+	r        io.Reader
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.progress.sent += int64(n)
+
+		pr.progress.fn(UploadProgress{
+			Field:    pr.field,
+			FileSent: pr.sent,
+			FileSize: pr.size,
+			Sent:     pr.progress.sent,
+			Total:    pr.progress.total,
+			Elapsed:  time.Since(pr.progress.start),
+		})
+	}
+
+	return n, err //nolint:wrapcheck
 }
@@ -1,13 +1,19 @@
 package oc_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	oc "github.com/navigacontentlab/oc-client-go/v2"
@@ -54,6 +60,97 @@ func TestRetrieveUploadVersion(t *testing.T) {
 	}
 }
 
+func TestClient_Upload__ContentLength(t *testing.T) {
+	var (
+		contentLength    int64
+		transferEncoding []string
+		bodySize         int
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		transferEncoding = r.TransferEncoding
+
+		body, _ := io.ReadAll(r.Body)
+
+		bodySize = len(body)
+
+		_, _ = io.WriteString(w, testImageUUID)
+	}))
+
+	t.Cleanup(ts.Close)
+
+	client, err := oc.New(oc.Options{BaseURL: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := bytes.Repeat([]byte("x"), 100*1024)
+	metadata := "<newsItem/>"
+
+	var last oc.UploadProgress
+
+	_, err = client.Upload(context.Background(), oc.UploadRequest{
+		Source: "test",
+		Files: oc.FileSet{
+			"file": {
+				Name:     "sample.jpeg",
+				Reader:   bytes.NewReader(image),
+				Mimetype: "image/jpeg",
+			},
+			"metadata": {
+				Name:     "sample.xml",
+				Reader:   strings.NewReader(metadata),
+				Mimetype: "application/vnd.iptc.g2.newsitem+xml",
+			},
+		},
+		ContentLength: true,
+		Progress: func(p oc.UploadProgress) {
+			last = p
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	if len(transferEncoding) != 0 {
+		t.Errorf("expected no transfer encoding, got %v", transferEncoding)
+	}
+
+	if contentLength <= 0 || contentLength != int64(bodySize) {
+		t.Errorf("content length %d doesn't match the body size %d", contentLength, bodySize)
+	}
+
+	total := int64(len(image) + len(metadata))
+
+	if last.Sent != total || last.Total != total || last.FileSent != last.FileSize {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+}
+
+func TestClient_Upload__ContentLengthUnknown(t *testing.T) {
+	client, err := oc.New(oc.Options{BaseURL: "http://localhost:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, pw := io.Pipe()
+
+	t.Cleanup(func() {
+		_ = pw.Close()
+	})
+
+	_, err = client.Upload(context.Background(), oc.UploadRequest{
+		Files: oc.FileSet{
+			"file": {Name: "stream.bin", Reader: pr, Mimetype: "application/octet-stream"},
+		},
+		ContentLength: true,
+	})
+	if err == nil {
+		t.Fatal("expected an upload with an unknown size to fail in content length mode")
+	}
+}
+
 // Will not actually be run since there is no output
 // because we cannot run this multiple times to the same
 // oc.
@@ -105,3 +202,199 @@ func ExampleClient_Upload() {
 		fmt.Println(err)
 	}
 }
+
+// checkGoroutines fails the test if the number of goroutines hasn't
+// returned to the count at the time of the call when the test ends.
+func checkGoroutines(t *testing.T) {
+	t.Helper()
+
+	before := runtime.NumGoroutine()
+
+	t.Cleanup(func() {
+		deadline := time.Now().Add(2 * time.Second)
+
+		for {
+			after := runtime.NumGoroutine()
+			if after <= before {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				buf := make([]byte, 1<<16)
+
+				n := runtime.Stack(buf, true)
+
+				t.Errorf("leaked %d goroutines:\n%s", after-before, buf[:n])
+
+				return
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+// newUploadTestClient starts a server with the given handler and
+// returns a client for it. Registered after checkGoroutines so that
+// the server and idle connections are gone before the goroutine
+// count is checked.
+func newUploadTestClient(t *testing.T, handler http.HandlerFunc) *oc.Client {
+	t.Helper()
+
+	ts := httptest.NewServer(handler)
+
+	transport := &http.Transport{}
+
+	t.Cleanup(func() {
+		transport.CloseIdleConnections()
+		ts.Close()
+	})
+
+	client, err := oc.New(oc.Options{
+		BaseURL:    ts.URL,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+type failingReader struct {
+	n   int
+	err error
+}
+
+func (fr *failingReader) Read(b []byte) (int, error) {
+	if fr.n <= 0 {
+		return 0, fr.err
+	}
+
+	n := min(len(b), fr.n)
+
+	fr.n -= n
+
+	return n, nil
+}
+
+func TestClient_Upload__ReaderError(t *testing.T) {
+	checkGoroutines(t)
+
+	client := newUploadTestClient(t, func(_ http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	})
+
+	readErr := errors.New("disk on fire")
+
+	_, err := client.Upload(context.Background(), oc.UploadRequest{
+		Files: oc.FileSet{
+			"file": {
+				Name:     "sample.jpeg",
+				Reader:   &failingReader{n: 64 * 1024, err: readErr},
+				Mimetype: "image/jpeg",
+			},
+		},
+	})
+	if !errors.Is(err, readErr) {
+		t.Fatalf("expected the reader error, got: %v", err)
+	}
+}
+
+func TestClient_Upload__EarlyResponse(t *testing.T) {
+	checkGoroutines(t)
+
+	client := newUploadTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		// Reject the upload without reading the body.
+		http.Error(w, "go away", http.StatusServiceUnavailable)
+	})
+
+	_, err := client.Upload(context.Background(), oc.UploadRequest{
+		Files: oc.FileSet{
+			"file": {
+				Name:     "sample.jpeg",
+				Reader:   bytes.NewReader(make([]byte, 8<<20)),
+				Mimetype: "image/jpeg",
+			},
+		},
+	})
+
+	var re *oc.ResponseError
+
+	// The transport may fail writing the body before it has read the
+	// response, but the upload must fail either way.
+	if err == nil {
+		t.Fatal("expected the upload to fail")
+	}
+
+	if errors.As(err, &re) && re.Response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status %q", re.Response.Status)
+	}
+}
+
+func TestClient_Upload__Cancel(t *testing.T) {
+	checkGoroutines(t)
+
+	release := make(chan struct{})
+
+	client := newUploadTestClient(t, func(_ http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		<-release
+	})
+
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Upload(ctx, oc.UploadRequest{
+		Files: oc.FileSet{
+			"file": {
+				Name:     "sample.jpeg",
+				Reader:   strings.NewReader("image"),
+				Mimetype: "image/jpeg",
+			},
+		},
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got: %v", err)
+	}
+}
+
+func TestClient_Upload__CancelBlockedReader(t *testing.T) {
+	checkGoroutines(t)
+
+	client := newUploadTestClient(t, func(_ http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	})
+
+	pr, pw := io.Pipe()
+
+	// Unblocks the body writer after Upload has returned.
+	t.Cleanup(func() { _ = pw.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := client.Upload(ctx, oc.UploadRequest{
+			Files: oc.FileSet{
+				"file": {Name: "stream.bin", Reader: pr, Mimetype: "application/octet-stream"},
+			},
+		})
+
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected a deadline exceeded error, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("upload didn't return after the context was cancelled")
+	}
+}
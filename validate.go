@@ -0,0 +1,283 @@
+package oc
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// UploadValidation enables pre-flight validation of an upload, so
+// that problems are reported before any file data is sent to OC.
+type UploadValidation struct {
+	// ContentType is the content type that the upload is expected
+	// to result in. If set it must exist in the OC schema.
+	ContentType string
+	// ContentTypes is used instead of fetching the schema from OC
+	// when checking the content type.
+	ContentTypes *ContentTypesResponse
+	// RequiredFields are the form fields that must be present.
+	// Defaults to the primary "file" field.
+	RequiredFields []string
+	// Mimetypes restricts the allowed mimetypes per form field.
+	// Entries can use wildcards like "image/*".
+	Mimetypes map[string][]string
+}
+
+// ValidationProblem describes a single problem with an upload or a
+// search request.
+type ValidationProblem struct {
+	// Field is the form field or request field with the problem,
+	// if any.
+	Field   string
+	Message string
+}
+
+func (p ValidationProblem) String() string {
+	if p.Field == "" {
+		return p.Message
+	}
+
+	return p.Field + ": " + p.Message
+}
+
+// ValidationError is returned when an upload or a search request
+// fails validation.
+type ValidationError struct {
+	Problems []ValidationProblem
+
+	// subject is what was validated, defaults to "upload".
+	subject string
+}
+
+// Error lists the validation problems.
+func (ve *ValidationError) Error() string {
+	problems := make([]string, len(ve.Problems))
+
+	for i, p := range ve.Problems {
+		problems[i] = p.String()
+	}
+
+	subject := ve.subject
+	if subject == "" {
+		subject = "upload"
+	}
+
+	return "invalid " + subject + ": " + strings.Join(problems, "; ")
+}
+
+func (ve *ValidationError) add(field string, format string, a ...interface{}) {
+	ve.Problems = append(ve.Problems, ValidationProblem{
+		Field:   field,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// ValidateUpload checks an upload request without sending it. A
+// *ValidationError is returned if the request has problems. XML
+// files are parsed to check that they are well-formed. Seekable
+// readers are rewound after they have been read, but non-seekable
+// readers for XML files are consumed, use UploadRequest.Validation
+// to validate and upload those in one go. The request file set isn't
+// modified.
+func (c *Client) ValidateUpload(ctx context.Context, req UploadRequest, v UploadValidation) error {
+	_, err := c.validateUpload(ctx, req, v)
+
+	return err
+}
+
+// validateUpload validates an upload request and returns a copy of
+// its file set where non-seekable XML readers have been replaced with
+// the buffered data, so that the files still can be uploaded.
+func (c *Client) validateUpload(ctx context.Context, req UploadRequest, v UploadValidation) (FileSet, error) {
+	var verr ValidationError
+
+	files := make(FileSet, len(req.Files))
+
+	for field, file := range req.Files {
+		files[field] = file
+	}
+
+	required := v.RequiredFields
+	if len(required) == 0 {
+		required = []string{"file"}
+	}
+
+	for _, field := range required {
+		if _, ok := files[field]; !ok {
+			verr.add(field, "the field is required")
+		}
+	}
+
+	fields := make([]string, 0, len(files))
+
+	for field := range files {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		err := validateFile(&verr, files, field, v.Mimetypes[field])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if v.ContentType != "" {
+		err := c.validateContentType(ctx, &verr, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(verr.Problems) > 0 {
+		return nil, &verr
+	}
+
+	return files, nil
+}
+
+func validateFile(verr *ValidationError, files FileSet, field string, allowed []string) error {
+	file := files[field]
+
+	if file.Name == "" {
+		verr.add(field, "the file has no name")
+	}
+
+	mediaType, _, err := mime.ParseMediaType(file.Mimetype)
+	if err != nil {
+		verr.add(field, "invalid mimetype %q", file.Mimetype)
+
+		return nil
+	}
+
+	if len(allowed) > 0 && !mimetypeAllowed(mediaType, allowed) {
+		verr.add(field, "mimetype %q isn't one of %s",
+			mediaType, strings.Join(allowed, ", "))
+	}
+
+	isMetadata := strings.HasPrefix(field, "metadata")
+
+	if isMetadata && !isXMLMimetype(mediaType) {
+		verr.add(field, "metadata must be XML, got %q", mediaType)
+	}
+
+	if file.Reader == nil || !(isMetadata || isXMLMimetype(mediaType)) {
+		return nil
+	}
+
+	data, err := peekFile(files, field)
+	if err != nil {
+		return err
+	}
+
+	if err := checkWellFormed(data); err != nil {
+		verr.add(field, "malformed XML: %v", err)
+	}
+
+	return nil
+}
+
+func mimetypeAllowed(mediaType string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == mediaType {
+			return true
+		}
+
+		prefix, ok := strings.CutSuffix(a, "/*")
+		if ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// peekFile reads the contents of a file without consuming it.
+func peekFile(files FileSet, field string) ([]byte, error) {
+	file := files[field]
+
+	if s, ok := file.Reader.(io.ReadSeeker); ok {
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get offset of %q: %w", field, err)
+		}
+
+		data, err := io.ReadAll(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", field, err)
+		}
+
+		if _, err := s.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind %q: %w", field, err)
+		}
+
+		return data, nil
+	}
+
+	data, err := io.ReadAll(file.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", field, err)
+	}
+
+	file.Reader = bytes.NewReader(data)
+	files[field] = file
+
+	return data, nil
+}
+
+func checkWellFormed(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	var root bool
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if _, ok := tok.(xml.StartElement); ok {
+			root = true
+		}
+	}
+
+	if !root {
+		return errors.New("no root element")
+	}
+
+	return nil
+}
+
+func (c *Client) validateContentType(ctx context.Context, verr *ValidationError, v UploadValidation) error {
+	schema := v.ContentTypes
+
+	if schema == nil {
+		var err error
+
+		schema, err = c.ContentTypes(ctx, ContentTypesRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to get content types: %w", err)
+		}
+	}
+
+	for _, ct := range schema.ContentTypes {
+		if ct.Name == v.ContentType {
+			return nil
+		}
+	}
+
+	verr.add("", "unknown content type %q", v.ContentType)
+
+	return nil
+}
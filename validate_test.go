@@ -0,0 +1,118 @@
+package oc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	oc "github.com/navigacontentlab/oc-client-go/v2"
+)
+
+func loadContentTypes(t *testing.T) *oc.ContentTypesResponse {
+	t.Helper()
+
+	var schema oc.ContentTypesResponse
+
+	loadTestData(t, "contenttypesresponse.json", &schema)
+
+	return &schema
+}
+
+func TestClient_Upload__Validation(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	schema := loadContentTypes(t)
+
+	_, err := client.Upload(context.Background(), oc.UploadRequest{
+		Files: oc.FileSet{
+			"metadata": {
+				Name:     "sample.xml",
+				Reader:   strings.NewReader("<newsItem><unclosed></newsItem>"),
+				Mimetype: "application/vnd.iptc.g2.newsitem+xml",
+			},
+			"preview": {
+				Name:     "preview.gif",
+				Reader:   strings.NewReader("GIF89a"),
+				Mimetype: "image/gif",
+			},
+		},
+		Validation: &oc.UploadValidation{
+			ContentType:  "Spaceship",
+			ContentTypes: schema,
+			Mimetypes: map[string][]string{
+				"preview": {"image/jpeg", "image/png"},
+			},
+		},
+	})
+
+	var verr *oc.ValidationError
+
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got: %v", err)
+	}
+
+	want := map[string]bool{
+		"file":     false,
+		"metadata": false,
+		"preview":  false,
+		"":         false,
+	}
+
+	for _, p := range verr.Problems {
+		want[p.Field] = true
+	}
+
+	for field, found := range want {
+		if !found {
+			t.Errorf("expected a problem for field %q, got: %v", field, verr)
+		}
+	}
+
+	if fake.Requests != 0 {
+		t.Errorf("expected no requests to be made, got %d", fake.Requests)
+	}
+}
+
+func TestClient_Upload__ValidationPasses(t *testing.T) {
+	fake, client := newFakeOC(t)
+
+	// Not seekable, so validation must buffer it.
+	metadata := io.MultiReader(strings.NewReader("<newsItem>ok</newsItem>"))
+
+	files := oc.FileSet{
+		"file": {
+			Name:     "sample.jpeg",
+			Reader:   strings.NewReader("image"),
+			Mimetype: "image/jpeg",
+		},
+		"metadata": {
+			Name:     "sample.xml",
+			Reader:   metadata,
+			Mimetype: "application/vnd.iptc.g2.newsitem+xml.picture",
+		},
+	}
+
+	res, err := client.Upload(context.Background(), oc.UploadRequest{
+		UUID:  testImageUUID,
+		Files: files,
+		Validation: &oc.UploadValidation{
+			ContentType:  "Image",
+			ContentTypes: loadContentTypes(t),
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected the upload to pass validation: %v", err)
+	}
+
+	v, _ := fake.Version(res.UUID, 0)
+
+	if got := string(v.Files["sample.xml"].Data); got != "<newsItem>ok</newsItem>" {
+		t.Errorf("unexpected metadata after validation: %q", got)
+	}
+
+	if files["metadata"].Reader != metadata {
+		t.Error("expected the file set of the request to be left unchanged")
+	}
+}